@@ -0,0 +1,154 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ungerik/go-enum/enums"
+)
+
+// commands are the sub-commands selected by the first argument.
+// Every command parses its own flags from args.
+var commands = map[string]func(args []string) error{
+	"ts":         typeScriptCommand,
+	"jsonschema": jsonSchemaCommand,
+	"catalog":    catalogCommand,
+	"list":       listCommand,
+	"doc":        docCommand,
+	"sql":        sqlCommand,
+	"compat":     compatCommand,
+	"snapshot":   snapshotCommand,
+}
+
+// commandFlags returns a FlagSet for a sub-command
+// with the -verbose, -print, and -validate flags
+// that are common to all commands generating files.
+func commandFlags(name string) (fs *flag.FlagSet, verbose, printOnly, validate *bool) {
+	fs = flag.NewFlagSet("go-enum "+name, flag.ExitOnError)
+	verbose = fs.Bool("verbose", false, "prints information to stdout of what's happening")
+	printOnly = fs.Bool("print", false, "prints to stdout instead of writing files")
+	validate = fs.Bool("validate", false, "check for missing or outdated files without modifying them")
+	return fs, verbose, printOnly, validate
+}
+
+// outputs returns the verbose and result writers for the common command flags.
+func outputs(verbose, printOnly bool) (verboseOut, resultOut io.Writer) {
+	if verbose {
+		verboseOut = os.Stdout
+	}
+	if printOnly {
+		resultOut = os.Stdout
+	}
+	return verboseOut, resultOut
+}
+
+// reportFindings prints the findings of the result
+// of a command to stderr and returns err.
+func reportFindings(result *enums.Result, err error) error {
+	if result != nil {
+		for _, finding := range result.Findings {
+			fmt.Fprintln(os.Stderr, finding)
+		}
+	}
+	return err
+}
+
+// pathArg returns the first positional argument or "."
+func pathArg(fs *flag.FlagSet) string {
+	if fs.NArg() > 0 {
+		return fs.Arg(0)
+	}
+	return "."
+}
+
+func typeScriptCommand(args []string) error {
+	fs, verbose, printOnly, validate := commandFlags("ts")
+	outDir := fs.String("out", "", "directory for the generated .ts files (default: the Go package directories)")
+	_ = fs.Parse(args)
+
+	verboseOut, resultOut := outputs(*verbose, *printOnly)
+	return reportFindings(enums.WriteTypeScript(pathArg(fs), *outDir, verboseOut, resultOut, *validate))
+}
+
+func jsonSchemaCommand(args []string) error {
+	fs, verbose, printOnly, validate := commandFlags("jsonschema")
+	outFile := fs.String("out", "", "file for the generated document (default: print to stdout)")
+	format := fs.String("format", enums.JSONSchemaFormat, "document format: json for JSON Schema, openapi for OpenAPI 3.1 components YAML")
+	_ = fs.Parse(args)
+
+	verboseOut, resultOut := outputs(*verbose, *printOnly || *outFile == "")
+	return reportFindings(enums.WriteJSONSchema(pathArg(fs), *outFile, *format, verboseOut, resultOut, *validate))
+}
+
+func catalogCommand(args []string) error {
+	fs, verbose, printOnly, validate := commandFlags("catalog")
+	outFile := fs.String("out", "", "file for the generated catalog (default: print to stdout)")
+	format := fs.String("format", enums.GotextFormat, "catalog format: gotext for gotext JSON, po for gettext")
+	lang := fs.String("lang", "en", "language of the labels in the source code")
+	_ = fs.Parse(args)
+
+	verboseOut, resultOut := outputs(*verbose, *printOnly || *outFile == "")
+	return reportFindings(enums.WriteCatalog(pathArg(fs), *outFile, *format, *lang, verboseOut, resultOut, *validate))
+}
+
+func docCommand(args []string) error {
+	fs, verbose, printOnly, validate := commandFlags("doc")
+	outFile := fs.String("out", "", "file for the generated documentation (default: print to stdout)")
+	format := fs.String("format", enums.MarkdownFormat, "documentation format: md for Markdown, html for a static HTML page")
+	sourceURL := fs.String("source-url", "", "URL of the path directory for source links, like https://github.com/org/repo/blob/main/ (default: links relative to -out)")
+	_ = fs.Parse(args)
+
+	verboseOut, resultOut := outputs(*verbose, *printOnly || *outFile == "")
+	return reportFindings(enums.WriteDoc(pathArg(fs), *outFile, *format, *sourceURL, verboseOut, resultOut, *validate))
+}
+
+func sqlCommand(args []string) error {
+	fs, verbose, printOnly, validate := commandFlags("sql")
+	outFile := fs.String("out", "", "file for the generated DDL (default: print to stdout)")
+	dialect := fs.String("dialect", enums.PostgresDialect, "SQL dialect: postgres, mysql, or sqlite")
+	base := fs.String("base", "", "git reference of the base version to write a migration from")
+	_ = fs.Parse(args)
+
+	verboseOut, resultOut := outputs(*verbose, *printOnly || *outFile == "")
+	return reportFindings(enums.WriteSQL(pathArg(fs), *outFile, *dialect, *base, verboseOut, resultOut, *validate))
+}
+
+func compatCommand(args []string) error {
+	fs := flag.NewFlagSet("go-enum compat", flag.ExitOnError)
+	base := fs.String("base", "", "baseline to compare with: a git reference or a snapshot .json file")
+	writeSnapshot := fs.String("write-snapshot", "", "file to write a snapshot of the current enums to, usable as -base")
+	_ = fs.Parse(args)
+
+	path := pathArg(fs)
+	if *base == "" && *writeSnapshot == "" {
+		return errors.New("compat requires -base or -write-snapshot")
+	}
+	if *base != "" {
+		if err := enums.CheckCompat(path, *base, os.Stdout); err != nil {
+			return err
+		}
+	}
+	if *writeSnapshot != "" {
+		return reportFindings(enums.WriteSnapshot(path, *writeSnapshot, nil, nil, false))
+	}
+	return nil
+}
+
+func snapshotCommand(args []string) error {
+	fs, verbose, printOnly, validate := commandFlags("snapshot")
+	_ = fs.Parse(args)
+
+	verboseOut, resultOut := outputs(*verbose, *printOnly)
+	return reportFindings(enums.WriteLockFile(pathArg(fs), verboseOut, resultOut, *validate))
+}
+
+func listCommand(args []string) error {
+	fs := flag.NewFlagSet("go-enum list", flag.ExitOnError)
+	format := fs.String("format", enums.ListTableFormat, "output format: table, json, or csv")
+	_ = fs.Parse(args)
+
+	return enums.WriteList(pathArg(fs), *format, os.Stdout)
+}
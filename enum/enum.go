@@ -0,0 +1,117 @@
+/*
+Package enum provides a generic interface for enum types generated by go-enum
+and helper functions to write generic code against them.
+
+Enum types with the ,assert flag get a compile-time assertion
+that they implement the interface:
+
+	type Status string //#enum,assert
+
+	var _ enum.Enum[Status] = Status("")
+*/
+package enum
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Enum is the method set generated for all enum types T.
+type Enum[T any] interface {
+	// Valid indicates if the value is any of the valid values
+	Valid() bool
+	// Validate returns an error if the value is none of the valid values
+	Validate() error
+	// Enums returns all valid values except deprecated ones
+	Enums() []T
+	// EnumStrings returns the values of Enums as strings
+	EnumStrings() []string
+}
+
+// ComparableEnum is an Enum that can be used as map key,
+// like all enum types generated for string and integer types.
+type ComparableEnum[T any] interface {
+	comparable
+	Enum[T]
+}
+
+// Parse returns the value of T for the string s
+// or an error if s is none of the valid values.
+//
+// If *T implements encoding.TextUnmarshaler, like for enums with
+// a generated Parse<Type> function, then it is used for parsing
+// so that aliases and normalization are supported.
+// The parsed value is validated, because the generated parse functions
+// return unrecognized strings as is or as the //#unknown value.
+// The unknown value is only returned for its own string.
+// Else s has to be one of the strings returned by EnumStrings
+// or the formatted value of one of the values returned by Enums.
+func Parse[T Enum[T]](s string) (T, error) {
+	var value, zero T
+	if u, ok := any(&value).(encoding.TextUnmarshaler); ok {
+		if err := u.UnmarshalText([]byte(s)); err != nil {
+			return zero, err
+		}
+		if err := value.Validate(); err != nil {
+			return zero, err
+		}
+		if u, ok := any(value).(interface{ IsUnknown() bool }); ok && u.IsUnknown() && fmt.Sprint(value) != s {
+			return zero, fmt.Errorf("invalid value %q for type %T", s, value)
+		}
+		return value, nil
+	}
+	strs := value.EnumStrings()
+	for i, v := range value.Enums() {
+		if (i < len(strs) && strs[i] == s) || fmt.Sprint(v) == s {
+			return v, nil
+		}
+	}
+	return value, fmt.Errorf("invalid value %q for type %T", s, value)
+}
+
+// ValidateAll returns the joined errors of
+// the Validate method of all values or nil.
+func ValidateAll[T Enum[T]](values ...T) error {
+	var errs []error
+	for _, value := range values {
+		if err := value.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// MapKeys returns the keys of m in the order of the values returned by Enums.
+// Other keys, like deprecated or invalid values, are appended
+// sorted by their string representation.
+func MapKeys[T ComparableEnum[T], V any](m map[T]V) []T {
+	var zero T
+	keys := make([]T, 0, len(m))
+	for _, value := range zero.Enums() {
+		if _, ok := m[value]; ok {
+			keys = append(keys, value)
+		}
+	}
+	if len(keys) == len(m) {
+		return keys
+	}
+	var others []T
+	for key := range m {
+		if !slices.Contains(keys, key) {
+			others = append(others, key)
+		}
+	}
+	slices.SortFunc(others, func(a, b T) int {
+		return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+	})
+	return append(keys, others...)
+}
+
+// Contains returns true if value is one of the values returned by Enums.
+// In contrast to the Valid method it returns false for deprecated values.
+func Contains[T ComparableEnum[T]](value T) bool {
+	return slices.Contains(value.Enums(), value)
+}
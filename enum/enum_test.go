@@ -0,0 +1,176 @@
+package enum
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testLevel int
+
+const (
+	testLevelLow  testLevel = 1
+	testLevelHigh testLevel = 2
+	testLevelOld  testLevel = 3 // Deprecated
+)
+
+func (l testLevel) Valid() bool {
+	return l == testLevelLow || l == testLevelHigh || l == testLevelOld
+}
+
+func (l testLevel) Validate() error {
+	if !l.Valid() {
+		return fmt.Errorf("invalid value %#v for type enum.testLevel", l)
+	}
+	return nil
+}
+
+func (testLevel) Enums() []testLevel { return []testLevel{testLevelLow, testLevelHigh} }
+
+func (testLevel) EnumStrings() []string { return []string{"1", "2"} }
+
+// testKind is an integer enum with the constant
+// names as EnumStrings like with ,string=name
+type testKind int
+
+const (
+	testKindA testKind = iota + 1
+	testKindB
+)
+
+func (k testKind) Valid() bool { return k == testKindA || k == testKindB }
+
+func (k testKind) Validate() error { return nil }
+
+func (testKind) Enums() []testKind { return []testKind{testKindA, testKindB} }
+
+func (testKind) EnumStrings() []string { return []string{"testKindA", "testKindB"} }
+
+// testColor implements encoding.TextUnmarshaler
+// like enums with a generated lenient Parse function
+// returning unrecognized strings as is
+type testColor string
+
+func (c testColor) Valid() bool { return c == "red" }
+
+func (c testColor) Validate() error {
+	if !c.Valid() {
+		return fmt.Errorf("invalid value %q for type enum.testColor", string(c))
+	}
+	return nil
+}
+
+func (testColor) Enums() []testColor { return []testColor{"red"} }
+
+func (testColor) EnumStrings() []string { return []string{"red"} }
+
+func (c *testColor) UnmarshalText(text []byte) error {
+	if strings.EqualFold(string(text), "red") {
+		*c = "red"
+	} else {
+		*c = testColor(text)
+	}
+	return nil
+}
+
+// testStatus implements encoding.TextUnmarshaler like
+// enums with a generated Parse function and an //#unknown value
+type testStatus string
+
+const (
+	testStatusActive testStatus = "active"
+	testStatusOther  testStatus = "other"
+)
+
+func (s testStatus) Valid() bool { return s == testStatusActive || s == testStatusOther }
+
+func (s testStatus) Validate() error { return nil }
+
+func (s testStatus) IsUnknown() bool { return s == testStatusOther || !s.Valid() }
+
+func (testStatus) Enums() []testStatus { return []testStatus{testStatusActive, testStatusOther} }
+
+func (testStatus) EnumStrings() []string { return []string{"active", "other"} }
+
+func (s *testStatus) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "active":
+		*s = testStatusActive
+	default:
+		*s = testStatusOther
+	}
+	return nil
+}
+
+var (
+	_ Enum[testLevel]  = testLevel(0)
+	_ Enum[testKind]   = testKind(0)
+	_ Enum[testColor]  = testColor("")
+	_ Enum[testStatus] = testStatus("")
+)
+
+func TestParse(t *testing.T) {
+	level, err := Parse[testLevel]("2")
+	require.NoError(t, err)
+	assert.Equal(t, testLevelHigh, level)
+
+	_, err = Parse[testLevel]("3")
+	assert.EqualError(t, err, `invalid value "3" for type enum.testLevel`)
+
+	color, err := Parse[testColor]("RED")
+	require.NoError(t, err)
+	assert.Equal(t, testColor("red"), color)
+
+	// The lenient UnmarshalText result is validated
+	color, err = Parse[testColor]("blue")
+	assert.EqualError(t, err, `invalid value "blue" for type enum.testColor`)
+	assert.Equal(t, testColor(""), color)
+
+	// Formatted values and EnumStrings are accepted
+	kind, err := Parse[testKind]("1")
+	require.NoError(t, err)
+	assert.Equal(t, testKindA, kind)
+	kind, err = Parse[testKind]("testKindB")
+	require.NoError(t, err)
+	assert.Equal(t, testKindB, kind)
+	_, err = Parse[testKind]("3")
+	assert.EqualError(t, err, `invalid value "3" for type enum.testKind`)
+
+	// The unknown value is only returned for its own string
+	status, err := Parse[testStatus]("other")
+	require.NoError(t, err)
+	assert.Equal(t, testStatusOther, status)
+	status, err = Parse[testStatus]("garbage")
+	assert.EqualError(t, err, `invalid value "garbage" for type enum.testStatus`)
+	assert.Equal(t, testStatus(""), status)
+}
+
+func TestValidateAll(t *testing.T) {
+	assert.NoError(t, ValidateAll[testLevel]())
+	assert.NoError(t, ValidateAll(testLevelLow, testLevelOld))
+
+	err := ValidateAll(testLevelLow, 7, 8)
+	require.Error(t, err)
+	assert.Equal(t, "invalid value 7 for type enum.testLevel\ninvalid value 8 for type enum.testLevel", err.Error())
+}
+
+func TestMapKeys(t *testing.T) {
+	assert.Empty(t, MapKeys(map[testLevel]string{}))
+	assert.Equal(t,
+		[]testLevel{testLevelLow, testLevelHigh},
+		MapKeys(map[testLevel]string{testLevelHigh: "high", testLevelLow: "low"}),
+	)
+	assert.Equal(t,
+		[]testLevel{testLevelHigh, testLevelOld, 9},
+		MapKeys(map[testLevel]bool{9: true, testLevelOld: true, testLevelHigh: true}),
+	)
+}
+
+func TestContains(t *testing.T) {
+	assert.True(t, Contains(testLevelLow))
+	assert.False(t, Contains(testLevelOld), "deprecated")
+	assert.False(t, Contains(testLevel(9)), "invalid")
+}
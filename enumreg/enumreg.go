@@ -0,0 +1,180 @@
+/*
+Package enumreg is the runtime registry of enum types generated by go-enum.
+
+Enum types with the ,registry flag register themselves in an init function
+generated next to their methods:
+
+	type Status string //#enum,registry
+
+The registered types can then be enumerated at runtime,
+for example by admin UIs or generic validators,
+and looked up by reflect.Type or by their fully qualified name:
+
+	info, ok := enumreg.LookupName("example.com/orders.Status")
+	for i, value := range info.Values {
+		fmt.Println(info.Names[i], value)
+	}
+*/
+package enumreg
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Enum is the method set generated for all enum types
+// that can be used without knowing the concrete type.
+type Enum interface {
+	// Valid indicates if the value is any of the valid values
+	Valid() bool
+	// Validate returns an error if the value is none of the valid values
+	Validate() error
+	// EnumStrings returns all valid values as strings
+	EnumStrings() []string
+}
+
+// Definition of an enum type T passed to Register
+// by the generated init function.
+type Definition[T Enum] struct {
+	// Names are the Go constant names of the values
+	Names []string
+	// Values are all values of the enum type in declaration order
+	Values []T
+	// Nullable indicates if Null is the null value of the enum type
+	Nullable bool
+	// Null is the null value if Nullable is true
+	Null T
+	// Descriptions are the doc comments of the values,
+	// nil if no value is documented
+	Descriptions []string
+}
+
+// Info describes a registered enum type.
+type Info struct {
+	// Type is the reflect.Type of the enum type
+	Type reflect.Type
+	// Name is the type name without package
+	Name string
+	// PkgPath is the import path of the package of the type
+	PkgPath string
+	// Names are the Go constant names of the values
+	Names []string
+	// Values are all values of the enum type in declaration order
+	Values []Enum
+	// Null is the null value of a nullable enum type, else nil
+	Null Enum
+	// Descriptions are the doc comments of the values,
+	// nil if no value is documented
+	Descriptions []string
+}
+
+// QualifiedName returns the import path of the package
+// and the type name separated by a dot, like "example.com/orders.Status".
+func (info *Info) QualifiedName() string {
+	return info.PkgPath + "." + info.Name
+}
+
+// IsNullable returns true if the enum type has a null value.
+func (info *Info) IsNullable() bool {
+	return info.Null != nil
+}
+
+// Description returns the description of the value
+// or an empty string if the value is not documented or not registered.
+func (info *Info) Description(value Enum) string {
+	i := slices.Index(info.Values, value)
+	if i < 0 || info.Descriptions == nil {
+		return ""
+	}
+	return info.Descriptions[i]
+}
+
+// String implements the fmt.Stringer interface.
+func (info *Info) String() string {
+	return info.QualifiedName()
+}
+
+var (
+	mtx    sync.RWMutex
+	byType = make(map[reflect.Type]*Info)
+	byName = make(map[string]*Info)
+)
+
+// Register the enum type T with its definition.
+// It is called by the init functions generated for the ,registry flag.
+//
+// Register panics if T is already registered or if the lengths
+// of the definition slices don't match, because both are
+// programming errors that should be detected at program start.
+func Register[T Enum](def Definition[T]) {
+	typ := reflect.TypeFor[T]()
+	if len(def.Names) != len(def.Values) || (def.Descriptions != nil && len(def.Descriptions) != len(def.Values)) {
+		panic(fmt.Sprintf("enumreg: definition of %s has %d names, %d values, and %d descriptions", typ, len(def.Names), len(def.Values), len(def.Descriptions)))
+	}
+	info := &Info{
+		Type:         typ,
+		Name:         typ.Name(),
+		PkgPath:      typ.PkgPath(),
+		Names:        slices.Clone(def.Names),
+		Values:       make([]Enum, len(def.Values)),
+		Descriptions: slices.Clone(def.Descriptions),
+	}
+	for i, value := range def.Values {
+		info.Values[i] = value
+	}
+	if def.Nullable {
+		info.Null = def.Null
+	}
+
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	if _, exists := byType[typ]; exists {
+		panic(fmt.Sprintf("enumreg: %s already registered", info.QualifiedName()))
+	}
+	byType[typ] = info
+	byName[info.QualifiedName()] = info
+}
+
+// Lookup returns the Info of the registered enum type typ.
+func Lookup(typ reflect.Type) (info *Info, ok bool) {
+	mtx.RLock()
+	defer mtx.RUnlock()
+
+	info, ok = byType[typ]
+	return info, ok
+}
+
+// LookupName returns the Info of the registered enum type
+// with the fully qualified name, see Info.QualifiedName.
+func LookupName(qualifiedName string) (info *Info, ok bool) {
+	mtx.RLock()
+	defer mtx.RUnlock()
+
+	info, ok = byName[qualifiedName]
+	return info, ok
+}
+
+// LookupValue returns the Info of the registered enum type of value.
+func LookupValue(value Enum) (info *Info, ok bool) {
+	return Lookup(reflect.TypeOf(value))
+}
+
+// All returns the Info of all registered enum types
+// sorted by their qualified names.
+func All() []*Info {
+	mtx.RLock()
+	defer mtx.RUnlock()
+
+	infos := make([]*Info, 0, len(byType))
+	for _, info := range byType {
+		infos = append(infos, info)
+	}
+	slices.SortFunc(infos, func(a, b *Info) int {
+		return strings.Compare(a.QualifiedName(), b.QualifiedName())
+	})
+	return infos
+}
@@ -0,0 +1,110 @@
+package enumreg
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testStatus string
+
+const (
+	testStatusNone   testStatus = ""
+	testStatusActive testStatus = "active"
+)
+
+func (s testStatus) Valid() bool {
+	return s == testStatusNone || s == testStatusActive
+}
+
+func (s testStatus) Validate() error {
+	if !s.Valid() {
+		return fmt.Errorf("invalid value %#v for type enumreg.testStatus", s)
+	}
+	return nil
+}
+
+func (testStatus) EnumStrings() []string {
+	return []string{"", "active"}
+}
+
+type testPriority int
+
+func (p testPriority) Valid() bool         { return p == 1 }
+func (p testPriority) Validate() error     { return nil }
+func (testPriority) EnumStrings() []string { return []string{"1"} }
+
+func init() {
+	Register(Definition[testStatus]{
+		Names:        []string{"testStatusNone", "testStatusActive"},
+		Values:       []testStatus{testStatusNone, testStatusActive},
+		Nullable:     true,
+		Null:         testStatusNone,
+		Descriptions: []string{"", "Active orders"},
+	})
+	Register(Definition[testPriority]{
+		Names:  []string{"testPriorityLow"},
+		Values: []testPriority{1},
+	})
+}
+
+func TestLookup(t *testing.T) {
+	info, ok := Lookup(reflect.TypeFor[testStatus]())
+	require.True(t, ok)
+	assert.Equal(t, "testStatus", info.Name)
+	assert.Equal(t, "github.com/ungerik/go-enum/enumreg", info.PkgPath)
+	assert.Equal(t, "github.com/ungerik/go-enum/enumreg.testStatus", info.QualifiedName())
+	assert.Equal(t, []string{"testStatusNone", "testStatusActive"}, info.Names)
+	assert.Equal(t, []Enum{testStatusNone, testStatusActive}, info.Values)
+	assert.True(t, info.IsNullable())
+	assert.Equal(t, Enum(testStatusNone), info.Null)
+	assert.Equal(t, "Active orders", info.Description(testStatusActive))
+	assert.Equal(t, "", info.Description(testStatus("unknown")))
+
+	byName, ok := LookupName("github.com/ungerik/go-enum/enumreg.testStatus")
+	require.True(t, ok)
+	assert.Same(t, info, byName)
+
+	byValue, ok := LookupValue(testStatusActive)
+	require.True(t, ok)
+	assert.Same(t, info, byValue)
+
+	priority, ok := Lookup(reflect.TypeFor[testPriority]())
+	require.True(t, ok)
+	assert.False(t, priority.IsNullable())
+	assert.Nil(t, priority.Descriptions)
+	assert.Equal(t, "", priority.Description(testPriority(1)))
+
+	_, ok = Lookup(reflect.TypeFor[string]())
+	assert.False(t, ok)
+	_, ok = LookupName("example.com/unknown.Type")
+	assert.False(t, ok)
+}
+
+func TestAll(t *testing.T) {
+	var names []string
+	for _, info := range All() {
+		names = append(names, info.QualifiedName())
+	}
+	assert.Equal(t, []string{
+		"github.com/ungerik/go-enum/enumreg.testPriority",
+		"github.com/ungerik/go-enum/enumreg.testStatus",
+	}, names)
+}
+
+func TestRegister_Panics(t *testing.T) {
+	assert.Panics(t, func() {
+		Register(Definition[testStatus]{
+			Names:  []string{"testStatusActive"},
+			Values: []testStatus{testStatusActive},
+		})
+	}, "already registered")
+	assert.Panics(t, func() {
+		Register(Definition[testStatus]{
+			Names: []string{"testStatusActive"},
+		})
+	}, "length mismatch")
+}
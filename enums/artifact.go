@@ -0,0 +1,86 @@
+package enums
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/token"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/ungerik/go-astvisit"
+)
+
+// writeArtifacts writes generated non-Go files like TypeScript declarations
+// and returns them as Result.Files with absolute paths.
+//
+// Files that already have the generated content are not touched.
+// If resultOut is not nil, the contents are written to resultOut instead of files.
+// If validate is true, no files are written and every missing or outdated
+// file is returned as Result.Findings together with an error, like with Run.
+func writeArtifacts(files map[string][]byte, verboseOut, resultOut io.Writer, validate bool) (*Result, error) {
+	start := time.Now()
+	result := new(Result)
+	for _, filePath := range slices.Sorted(maps.Keys(files)) {
+		content := files[filePath]
+		absPath, err := filepath.Abs(filePath)
+		if err != nil {
+			return nil, err
+		}
+		fileResult := &FileResult{Path: absPath}
+		result.Files = append(result.Files, fileResult)
+		existing, err := os.ReadFile(filePath)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		if err == nil {
+			fileResult.Source = existing
+		}
+		if err == nil && bytes.Equal(existing, content) && resultOut == nil {
+			if err := astvisit.FprintfVerbose(verboseOut, "no changes in file: %s\n", filePath); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		fileResult.Output = content
+		switch {
+		case validate:
+			result.Findings = append(result.Findings, Finding{
+				Pos:     token.Position{Filename: filePath},
+				Message: "missing or outdated",
+			})
+		case resultOut != nil:
+			if _, err := resultOut.Write(content); err != nil {
+				return nil, err
+			}
+		default:
+			if err := astvisit.FprintfVerbose(verboseOut, "writing file: %s\n", filePath); err != nil {
+				return nil, err
+			}
+			if err := writeFile(filePath, content); err != nil {
+				return nil, err
+			}
+		}
+	}
+	result.Duration = time.Since(start)
+
+	if len(result.Findings) > 0 {
+		return result, fmt.Errorf("found %d missing or outdated generated file(s)", len(result.Findings))
+	}
+	return result, nil
+}
+
+// writeFile writes a generated file,
+// creating its directory if it doesn't exist.
+func writeFile(filePath string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+	// File permissions 0644 are appropriate for generated files
+	return os.WriteFile(filePath, content, 0644) //#nosec G306
+}
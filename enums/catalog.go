@@ -0,0 +1,156 @@
+package enums
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Formats of the message catalog written by WriteCatalog.
+const (
+	// GotextFormat writes a gotext JSON catalog for golang.org/x/text/message/pipeline
+	GotextFormat = "gotext"
+	// GettextFormat writes a gettext .po file
+	GettextFormat = "po"
+)
+
+// CatalogKey returns the message catalog key "<Type>.<Const>"
+// of the enum constant with the index.
+func (e *Enum) CatalogKey(index int) string {
+	return e.Type + "." + e.Enums[index]
+}
+
+// Catalog returns a message catalog with the labels of all constants
+// of the passed enums as source messages for the language lang,
+// keyed by "<Type>.<Const>" like expected by the generated LabelFor methods.
+// The descriptions of the constants are added as comments for translators.
+// Enums without the ,i18n flag and //#label markers are skipped,
+// see Enum.HasCatalog, as are empty labels like the one of a null value,
+// because an empty message ID is reserved for the gettext header.
+//
+// The format is either GotextFormat or GettextFormat.
+// The file paths of the gettext source references are relative to sourceDir.
+func Catalog(enums []*Enum, format, lang, sourceDir string) ([]byte, error) {
+	enums = slices.DeleteFunc(slices.Clone(enums), func(e *Enum) bool { return !e.HasCatalog() })
+	var b bytes.Buffer
+	switch format {
+	case GotextFormat:
+		type gotextMessage struct {
+			ID                string `json:"id"`
+			Message           string `json:"message"`
+			Translation       string `json:"translation"`
+			TranslatorComment string `json:"translatorComment,omitempty"`
+		}
+		catalog := struct {
+			Language string          `json:"language"`
+			Messages []gotextMessage `json:"messages"`
+		}{
+			Language: lang,
+			Messages: []gotextMessage{},
+		}
+		for _, enum := range enums {
+			// gotext messages are format strings
+			for i, label := range enum.MessageLabels() {
+				if label == "" {
+					continue
+				}
+				catalog.Messages = append(catalog.Messages, gotextMessage{
+					ID:                enum.CatalogKey(i),
+					Message:           label,
+					Translation:       label,
+					TranslatorComment: enum.Descriptions[i],
+				})
+			}
+		}
+		enc := json.NewEncoder(&b)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(catalog); err != nil {
+			return nil, err
+		}
+	case GettextFormat:
+		fmt.Fprintf(&b, "msgid \"\"\nmsgstr \"\"\n%s\n%s\n",
+			strconv.Quote("Language: "+lang+"\n"),
+			strconv.Quote("Content-Type: text/plain; charset=UTF-8\n"),
+		)
+		for _, enum := range enums {
+			file := filepath.Join(enum.Dir, enum.File)
+			if rel, err := filepath.Rel(sourceDir, file); err == nil {
+				file = rel
+			}
+			file = filepath.ToSlash(file)
+			for i, label := range enum.DisplayLabels() {
+				if label == "" {
+					continue
+				}
+				b.WriteString("\n")
+				for _, line := range strings.Split(enum.Descriptions[i], "\n") {
+					if line != "" {
+						fmt.Fprintf(&b, "#. %s\n", line)
+					}
+				}
+				line := enum.Line
+				if i < len(enum.ValueLines) {
+					line = enum.ValueLines[i]
+				}
+				fmt.Fprintf(&b, "#: %s:%d\n", file, line)
+				fmt.Fprintf(&b, "msgctxt %s\n", strconv.Quote(enum.CatalogKey(i)))
+				fmt.Fprintf(&b, "msgid %s\n", strconv.Quote(label))
+				fmt.Fprintf(&b, "msgstr %s\n", strconv.Quote(label))
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown catalog format %q, expected %q or %q", format, GotextFormat, GettextFormat)
+	}
+	return b.Bytes(), nil
+}
+
+// WriteCatalog writes a message catalog with the labels
+// of the enums found at path, see Catalog.
+//
+// The gettext source references are relative to the directory of outFile,
+// or to the directory of path if the catalog is only written to resultOut.
+//
+// Parameters:
+//   - path: Directory or file path to process, may end with "..." to recurse
+//   - outFile: File for the generated catalog
+//   - format: GotextFormat or GettextFormat
+//   - lang: BCP 47 language tag of the labels in the source code
+//   - verboseOut: Writer for verbose progress output (nil to disable)
+//   - resultOut: Writer for generated catalog output (nil to write to outFile)
+//   - validate: If true, only check that outFile is up to date, an outdated file is returned as Result.Findings
+func WriteCatalog(path, outFile, format, lang string, verboseOut, resultOut io.Writer, validate bool) (*Result, error) {
+	if outFile == "" && (resultOut == nil || validate) {
+		return nil, errors.New("no output file for message catalog")
+	}
+	enums, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	sourceDir, err := filepath.Abs(strings.TrimSuffix(path, "..."))
+	if err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(sourceDir); err == nil && !info.IsDir() {
+		sourceDir = filepath.Dir(sourceDir)
+	}
+	if outFile != "" {
+		absOutFile, err := filepath.Abs(outFile)
+		if err != nil {
+			return nil, err
+		}
+		sourceDir = filepath.Dir(absOutFile)
+	}
+	catalog, err := Catalog(enums, format, lang, sourceDir)
+	if err != nil {
+		return nil, err
+	}
+	return writeArtifacts(map[string][]byte{outFile: catalog}, verboseOut, resultOut, validate)
+}
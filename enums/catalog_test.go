@@ -0,0 +1,124 @@
+package enums
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const catalogTestSource = `package example
+
+type Status string //#enum
+
+const (
+	// StatusOnHold waits for the customer
+	StatusOnHold Status = "on_hold" //#label:"On hold"
+	StatusActive Status = "active"
+)
+
+type Priority int //#enum
+
+const PriorityLow Priority = 1
+`
+
+func TestCatalog_Gotext(t *testing.T) {
+	fset, pkg, astFile := parseSource(t, catalogTestSource)
+	enums, err := Find(fset, pkg, astFile)
+	require.NoError(t, err)
+
+	catalog, err := Catalog([]*Enum{enums["Status"], enums["Priority"]}, GotextFormat, "en", ".")
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"language": "en",
+		"messages": [
+			{
+				"id": "Status.StatusOnHold",
+				"message": "On hold",
+				"translation": "On hold",
+				"translatorComment": "StatusOnHold waits for the customer"
+			},
+			{
+				"id": "Status.StatusActive",
+				"message": "active",
+				"translation": "active"
+			}
+		]
+	}`, string(catalog))
+}
+
+func TestCatalog_Gettext(t *testing.T) {
+	fset, pkg, astFile := parseSource(t, catalogTestSource)
+	enums, err := Find(fset, pkg, astFile)
+	require.NoError(t, err)
+
+	catalog, err := Catalog([]*Enum{enums["Status"], enums["Priority"]}, GettextFormat, "de", ".")
+	require.NoError(t, err)
+
+	assert.Equal(t, `msgid ""
+msgstr ""
+"Language: de\n"
+"Content-Type: text/plain; charset=UTF-8\n"
+
+#. StatusOnHold waits for the customer
+#: test.go:7
+msgctxt "Status.StatusOnHold"
+msgid "On hold"
+msgstr "On hold"
+
+#: test.go:8
+msgctxt "Status.StatusActive"
+msgid "active"
+msgstr "active"
+`, string(catalog))
+
+	_, err = Catalog([]*Enum{enums["Status"]}, "xliff", "de", ".")
+	assert.ErrorContains(t, err, "unknown catalog format")
+}
+
+func TestCatalog_SkipsEmptyLabels(t *testing.T) {
+	fset, pkg, astFile := parseSource(t, `package example
+
+type Status string //#enum,i18n
+
+const (
+	StatusNull   Status = "" //#null
+	StatusActive Status = "active"
+)
+`)
+	enums, err := Find(fset, pkg, astFile)
+	require.NoError(t, err)
+
+	catalog, err := Catalog([]*Enum{enums["Status"]}, GettextFormat, "en", ".")
+	require.NoError(t, err)
+	assert.Equal(t, 1, bytes.Count(catalog, []byte(`msgid ""`)), "only the header has an empty msgid")
+	assert.NotContains(t, string(catalog), "Status.StatusNull")
+	assert.Contains(t, string(catalog), `msgctxt "Status.StatusActive"`)
+
+	catalog, err = Catalog([]*Enum{enums["Status"]}, GotextFormat, "en", ".")
+	require.NoError(t, err)
+	assert.NotContains(t, string(catalog), "Status.StatusNull")
+	assert.Contains(t, string(catalog), `"id": "Status.StatusActive"`)
+}
+
+func TestWriteCatalog_References(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "models"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "models", "models.go"), []byte(catalogTestSource), 0644))
+	outFile := filepath.Join(tmpDir, "locales", "en.po")
+
+	_, err := WriteCatalog(tmpDir+"/...", outFile, GettextFormat, "en", nil, nil, false)
+	require.NoError(t, err)
+	catalog, err := os.ReadFile(outFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(catalog), "#: ../models/models.go:7\n")
+
+	var out bytes.Buffer
+	_, err = WriteCatalog(tmpDir+"/...", "", GettextFormat, "en", nil, &out, false)
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "#: models/models.go:7\n")
+}
@@ -0,0 +1,201 @@
+package enums
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// CompatChange is a difference between the enums
+// of a baseline Snapshot and the current enums.
+type CompatChange struct {
+	// Enum is the qualified name of the enum type
+	Enum string
+	// Value is the name of the changed enum constant,
+	// empty for changes of the enum type
+	Value string
+	// Message describes the change
+	Message string
+	// Breaking is true for changes that break stored data
+	// or API clients using the baseline values
+	Breaking bool
+}
+
+// String returns the change formatted for a report.
+func (c CompatChange) String() string {
+	kind := "non-breaking"
+	if c.Breaking {
+		kind = "breaking"
+	}
+	if c.Value == "" {
+		return fmt.Sprintf("%s: %s: %s", kind, c.Enum, c.Message)
+	}
+	return fmt.Sprintf("%s: %s.%s: %s", kind, c.Enum, c.Value, c.Message)
+}
+
+// CompareSnapshots returns the changes from the base to the current snapshot.
+//
+// Removed enums and values, changed literals, changed underlying types,
+// and a changed null value are breaking changes, added enums and values are not.
+// The changes are sorted by enum name and value order.
+func CompareSnapshots(base, current *Snapshot) []CompatChange {
+	var changes []CompatChange
+	currentEnums := make(map[string]*SnapshotEnum, len(current.Enums))
+	for i := range current.Enums {
+		currentEnums[current.Enums[i].Name] = &current.Enums[i]
+	}
+	baseEnums := make(map[string]bool, len(base.Enums))
+	for i := range base.Enums {
+		baseEnum := &base.Enums[i]
+		baseEnums[baseEnum.Name] = true
+		currentEnum, ok := currentEnums[baseEnum.Name]
+		if !ok {
+			changes = append(changes, CompatChange{Enum: baseEnum.Name, Message: "enum type was removed", Breaking: true})
+			continue
+		}
+		changes = append(changes, compareSnapshotEnums(baseEnum, currentEnum)...)
+	}
+	for _, currentEnum := range current.Enums {
+		if !baseEnums[currentEnum.Name] {
+			changes = append(changes, CompatChange{Enum: currentEnum.Name, Message: "enum type was added"})
+		}
+	}
+	// Keep the order of the changes per enum
+	slices.SortStableFunc(changes, func(a, b CompatChange) int {
+		return strings.Compare(a.Enum, b.Enum)
+	})
+	return changes
+}
+
+// compareSnapshotEnums returns the changes of an enum type.
+func compareSnapshotEnums(base, current *SnapshotEnum) []CompatChange {
+	var changes []CompatChange
+	if base.Underlying != current.Underlying {
+		changes = append(changes, CompatChange{
+			Enum:     current.Name,
+			Message:  fmt.Sprintf("underlying type changed from %s to %s", base.Underlying, current.Underlying),
+			Breaking: true,
+		})
+	}
+	if base.Null != current.Null {
+		changes = append(changes, CompatChange{
+			Enum:     current.Name,
+			Message:  fmt.Sprintf("null value changed from %s to %s", orNone(base.Null), orNone(current.Null)),
+			Breaking: true,
+		})
+	}
+	currentValues := make(map[string]SnapshotValue, len(current.Values))
+	for _, value := range current.Values {
+		currentValues[value.Name] = value
+	}
+	baseValues := make(map[string]bool, len(base.Values))
+	for _, baseValue := range base.Values {
+		baseValues[baseValue.Name] = true
+		currentValue, ok := currentValues[baseValue.Name]
+		switch {
+		case !ok:
+			changes = append(changes, CompatChange{
+				Enum:     current.Name,
+				Value:    baseValue.Name,
+				Message:  fmt.Sprintf("value %s was removed", baseValue.Literal),
+				Breaking: true,
+			})
+		case !sameLiteral(baseValue.Literal, currentValue.Literal):
+			changes = append(changes, CompatChange{
+				Enum:     current.Name,
+				Value:    baseValue.Name,
+				Message:  fmt.Sprintf("literal changed from %s to %s", baseValue.Literal, currentValue.Literal),
+				Breaking: true,
+			})
+		}
+	}
+	for _, value := range current.Values {
+		if !baseValues[value.Name] {
+			changes = append(changes, CompatChange{
+				Enum:    current.Name,
+				Value:   value.Name,
+				Message: fmt.Sprintf("value %s was added", value.Literal),
+			})
+		}
+	}
+	return changes
+}
+
+// sameLiteral returns true if the JSON literals have the same value,
+// independent of their formatting.
+func sameLiteral(a, b json.RawMessage) bool {
+	if bytes.Equal(a, b) {
+		return true
+	}
+	var valueA, valueB any
+	decA := json.NewDecoder(bytes.NewReader(a))
+	decA.UseNumber()
+	decB := json.NewDecoder(bytes.NewReader(b))
+	decB.UseNumber()
+	if decA.Decode(&valueA) != nil || decB.Decode(&valueB) != nil {
+		return false
+	}
+	return reflect.DeepEqual(valueA, valueB)
+}
+
+// orNone returns name or "none" for an empty name.
+func orNone(name string) string {
+	if name == "" {
+		return "none"
+	}
+	return name
+}
+
+// LoadBaseline returns the Snapshot of the baseline base,
+// either a snapshot JSON file if base ends with ".json",
+// or else a git reference of the enums at path, see LoadGitRef.
+func LoadBaseline(base, path string) (*Snapshot, error) {
+	if strings.HasSuffix(base, ".json") {
+		return LoadSnapshot(base)
+	}
+	enums, err := LoadGitRef(base, path)
+	if err != nil {
+		return nil, err
+	}
+	return NewSnapshot(enums)
+}
+
+// CheckCompat compares the enums found at path with the baseline base,
+// see LoadBaseline, and writes every change to out, see CompareSnapshots.
+// It returns an error if there are breaking changes.
+//
+// Parameters:
+//   - path: Directory or file path to process, may end with "..." to recurse
+//   - base: Snapshot JSON file or git reference of the baseline
+//   - out: Writer for the report of the changes
+func CheckCompat(path, base string, out io.Writer) error {
+	baseline, err := LoadBaseline(base, path)
+	if err != nil {
+		return err
+	}
+	enums, err := Load(path)
+	if err != nil {
+		return err
+	}
+	current, err := NewSnapshot(enums)
+	if err != nil {
+		return err
+	}
+	breaking := 0
+	for _, change := range CompareSnapshots(baseline, current) {
+		if _, err := fmt.Fprintln(out, change); err != nil {
+			return err
+		}
+		if change.Breaking {
+			breaking++
+		}
+	}
+	if breaking > 0 {
+		return fmt.Errorf("found %d breaking enum change(s) compared to %s", breaking, base)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package enums
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const compatTestSource = `package models
+
+type Status string //#enum
+
+const (
+	StatusNull    Status = "" //#null
+	StatusActive  Status = "active"
+	StatusPending Status = "pending"
+)
+
+type Priority int //#enum
+
+const (
+	PriorityLow  Priority = 1
+	PriorityHigh Priority = 2
+)
+`
+
+func writeCompatTestModule(t *testing.T, source string) string {
+	t.Helper()
+	tmpDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "models"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module example.com/test\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "models", "models.go"), []byte(source), 0644))
+	return tmpDir
+}
+
+func TestCompareSnapshots(t *testing.T) {
+	base, err := NewSnapshot(findCompatTestEnums(t, compatTestSource))
+	require.NoError(t, err)
+	current, err := NewSnapshot(findCompatTestEnums(t, `package models
+
+type Status string //#enum
+
+const (
+	StatusActive   Status = "enabled"
+	StatusPending  Status = "pending"
+	StatusArchived Status = "archived"
+)
+
+type Priority int64 //#enum
+
+const (
+	PriorityLow  Priority = 1
+)
+
+type Color string //#enum
+
+const ColorRed Color = "red"
+`))
+	require.NoError(t, err)
+
+	var report []string
+	for _, change := range CompareSnapshots(base, current) {
+		report = append(report, change.String())
+	}
+	assert.Equal(t, []string{
+		"non-breaking: models.Color: enum type was added",
+		"breaking: models.Priority: underlying type changed from int to int64",
+		"breaking: models.Priority.PriorityHigh: value 2 was removed",
+		"breaking: models.Status: null value changed from StatusNull to none",
+		`breaking: models.Status.StatusNull: value "" was removed`,
+		`breaking: models.Status.StatusActive: literal changed from "active" to "enabled"`,
+		`non-breaking: models.Status.StatusArchived: value "archived" was added`,
+	}, report)
+
+	changes := CompareSnapshots(current, base)
+	assert.Equal(t, CompatChange{Enum: "models.Color", Message: "enum type was removed", Breaking: true}, changes[0])
+}
+
+func findCompatTestEnums(t *testing.T, source string) []*Enum {
+	t.Helper()
+	fset, pkg, astFile := parseSource(t, source)
+	enums, err := Find(fset, pkg, astFile)
+	require.NoError(t, err)
+	var result []*Enum
+	for _, enum := range enums {
+		result = append(result, enum)
+	}
+	return result
+}
+
+func TestCheckCompat(t *testing.T) {
+	tmpDir := writeCompatTestModule(t, compatTestSource)
+	snapshotFile := filepath.Join(tmpDir, "snapshot.json")
+	_, err := WriteSnapshot(tmpDir+"/...", snapshotFile, nil, nil, false)
+	require.NoError(t, err)
+
+	// Added values are not breaking
+	source := strings.Replace(compatTestSource, "PriorityHigh Priority = 2\n", "PriorityHigh Priority = 2\n\tPriorityUrgent Priority = 3\n", 1)
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "models", "models.go"), []byte(source), 0644))
+	var out bytes.Buffer
+	require.NoError(t, CheckCompat(tmpDir+"/...", snapshotFile, &out))
+	assert.Equal(t, "non-breaking: example.com/test/models.Priority.PriorityUrgent: value 3 was added\n", out.String())
+
+	// Changed literals are
+	source = strings.Replace(source, `"pending"`, `"waiting"`, 1)
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "models", "models.go"), []byte(source), 0644))
+	out.Reset()
+	err = CheckCompat(tmpDir+"/...", snapshotFile, &out)
+	require.EqualError(t, err, "found 1 breaking enum change(s) compared to "+snapshotFile)
+	assert.Contains(t, out.String(), `breaking: example.com/test/models.Status.StatusPending: literal changed from "pending" to "waiting"`)
+
+	t.Run("GitRef", func(t *testing.T) {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git not installed")
+		}
+		gitDir := writeCompatTestModule(t, compatTestSource)
+		for _, args := range [][]string{{"init", "-q"}, {"add", "-A"}, {"commit", "-q", "-m", "base"}} {
+			cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+			cmd.Dir = gitDir
+			output, err := cmd.CombinedOutput()
+			require.NoError(t, err, string(output))
+		}
+		require.NoError(t, os.WriteFile(filepath.Join(gitDir, "models", "models.go"), []byte(source), 0644))
+
+		var out bytes.Buffer
+		err := CheckCompat(filepath.Join(gitDir, "models"), "HEAD", &out)
+		require.EqualError(t, err, "found 1 breaking enum change(s) compared to HEAD", out.String())
+		assert.Equal(t, ""+
+			"non-breaking: example.com/test/models.Priority.PriorityUrgent: value 3 was added\n"+
+			"breaking: example.com/test/models.Status.StatusPending: literal changed from \"pending\" to \"waiting\"\n",
+			out.String())
+	})
+}
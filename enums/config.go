@@ -0,0 +1,267 @@
+package enums
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileName is the name of the project configuration file
+// that is looked up in the module root directory.
+const ConfigFileName = "go-enum.yaml"
+
+// Output file layouts of the generated declarations
+const (
+	// LayoutInline writes the generated declarations
+	// into the source file of the enum type
+	LayoutInline = "inline"
+	// LayoutFile writes the generated declarations of a source file
+	// into a separate file with the suffix GeneratedFileSuffix
+	LayoutFile = "file"
+)
+
+// GeneratedFileSuffix replaces the ".go" extension of a source file
+// for the generated file of the LayoutFile layout.
+const GeneratedFileSuffix = "_enum.go"
+
+// Strategies for the String method of integer enums
+const (
+	// IntStringNumber returns the number of the value,
+	// the String method is only generated for command line flags
+	IntStringNumber = "number"
+	// IntStringName returns the name of the constant of valid values
+	// and the number of invalid values
+	IntStringName = "name"
+)
+
+// Config is the project configuration read from the ConfigFileName file.
+//
+// Example go-enum.yaml:
+//
+//	options: [jsonschema, description]
+//	layout: file
+//	strict: true
+//	intString: name
+//	templates: tools/enumtemplates
+//	packages:
+//	  internal/legacy:
+//	    layout: inline
+//	    strict: false
+//	  api/...:
+//	    options: [jsonschema, slog]
+type Config struct {
+	// Options are //#enum options applied to every enum type
+	// before the options of the type itself.
+	// String enum only options like nocase are not applied to other enums.
+	Options []string `yaml:"options"`
+	// Layout is LayoutInline (default) or LayoutFile
+	Layout string `yaml:"layout"`
+	// Strict enables the ,strict option for every enum type
+	Strict bool `yaml:"strict"`
+	// IntString is the strategy for the String method of integer enums:
+	// IntStringNumber (default) or IntStringName
+	IntString string `yaml:"intString"`
+	// Templates is the directory of user-defined templates
+	// relative to the configuration file, see LoadTemplates
+	Templates string `yaml:"templates"`
+	// Packages are overrides for package directories relative to the
+	// configuration file. A directory ending with "/..." also applies
+	// to all sub-directories, the most specific match is used.
+	Packages map[string]PackageConfig `yaml:"packages"`
+
+	// Dir is the directory that relative paths are resolved against,
+	// set by LoadConfig to the directory of the configuration file
+	Dir string `yaml:"-"`
+}
+
+// PackageConfig overrides the Config for packages.
+// Fields that are not set keep the value of the Config,
+// set Options replace the Options of the Config.
+type PackageConfig struct {
+	Options   []string `yaml:"options"`
+	Layout    string   `yaml:"layout"`
+	Strict    *bool    `yaml:"strict"`
+	IntString string   `yaml:"intString"`
+}
+
+// LoadConfig reads the configuration file at filePath.
+func LoadConfig(filePath string) (*Config, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	var config Config
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+	config.Dir, err = filepath.Abs(filepath.Dir(filePath))
+	if err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// FindConfig looks for the ConfigFileName file in the module root
+// of path, that is the first parent directory with a go.mod file.
+// It returns nil without error if there is no module root
+// or the module root has no configuration file.
+func FindConfig(path string) (*Config, error) {
+	dir, err := filepath.Abs(strings.TrimSuffix(path, "..."))
+	if err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(dir); err == nil && !info.IsDir() {
+		dir = filepath.Dir(dir)
+	}
+	root := moduleRoot(dir)
+	if root == "" {
+		return nil, nil
+	}
+	config, err := LoadConfig(filepath.Join(root, ConfigFileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return config, err
+}
+
+// moduleRoot returns the first parent directory of dir
+// with a go.mod file, or an empty string if there is none.
+func moduleRoot(dir string) string {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// TemplatesDir returns the Templates directory
+// resolved against Dir, or an empty string if not set.
+func (c *Config) TemplatesDir() string {
+	if c == nil || c.Templates == "" {
+		return ""
+	}
+	if filepath.IsAbs(c.Templates) {
+		return c.Templates
+	}
+	return filepath.Join(c.Dir, c.Templates)
+}
+
+// ForDir returns the configuration for the package in dir
+// with the most specific of the Packages overrides applied.
+// A nil Config returns nil.
+func (c *Config) ForDir(dir string) *Config {
+	if c == nil {
+		return nil
+	}
+	result := *c
+	result.Packages = nil
+	rel, err := filepath.Rel(c.Dir, dir)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return &result
+	}
+	rel = filepath.ToSlash(rel)
+
+	var (
+		override PackageConfig
+		best     = -1
+	)
+	for pattern, pkg := range c.Packages {
+		if rank := matchPackagePattern(pattern, rel); rank > best {
+			override, best = pkg, rank
+		}
+	}
+	if best < 0 {
+		return &result
+	}
+	if override.Options != nil {
+		result.Options = override.Options
+	}
+	if override.Layout != "" {
+		result.Layout = override.Layout
+	}
+	if override.Strict != nil {
+		result.Strict = *override.Strict
+	}
+	if override.IntString != "" {
+		result.IntString = override.IntString
+	}
+	return &result
+}
+
+// matchPackagePattern returns how specific the pattern of Config.Packages
+// matches the slash separated directory rel, or -1 if it doesn't match.
+// Longer patterns are more specific, and an exact pattern is more specific
+// than a "/..." pattern for the same directory.
+func matchPackagePattern(pattern, rel string) int {
+	pattern = path.Clean(strings.TrimPrefix(pattern, "./"))
+	if prefix, ok := strings.CutSuffix(pattern, "..."); ok {
+		prefix = path.Clean(prefix)
+		if prefix == "." || rel == prefix || strings.HasPrefix(rel, prefix+"/") {
+			return 2 * len(prefix)
+		}
+		return -1
+	}
+	if rel == pattern {
+		return 2*len(pattern) + 1
+	}
+	return -1
+}
+
+func (c *Config) validate() error {
+	if err := validateLayout(c.Layout); err != nil {
+		return err
+	}
+	if err := validateIntString(c.IntString); err != nil {
+		return err
+	}
+	patterns := make([]string, 0, len(c.Packages))
+	for pattern := range c.Packages {
+		patterns = append(patterns, pattern)
+	}
+	slices.Sort(patterns)
+	for _, pattern := range patterns {
+		pkg := c.Packages[pattern]
+		if err := validateLayout(pkg.Layout); err != nil {
+			return fmt.Errorf("package %s: %w", pattern, err)
+		}
+		if err := validateIntString(pkg.IntString); err != nil {
+			return fmt.Errorf("package %s: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+func validateLayout(layout string) error {
+	switch layout {
+	case "", LayoutInline, LayoutFile:
+		return nil
+	}
+	return fmt.Errorf("unknown layout %q, expected %s or %s", layout, LayoutInline, LayoutFile)
+}
+
+func validateIntString(intString string) error {
+	switch intString {
+	case "", IntStringNumber, IntStringName:
+		return nil
+	}
+	return fmt.Errorf("unknown intString %q, expected %s or %s", intString, IntStringNumber, IntStringName)
+}
@@ -0,0 +1,134 @@
+package enums
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, ConfigFileName)
+
+	config := `options: [jsonschema, description]
+layout: file
+strict: true
+intString: name
+templates: tools/templates
+packages:
+  internal/legacy:
+    layout: inline
+    strict: false
+  api/...:
+    options: [slog]
+`
+	require.NoError(t, os.WriteFile(configFile, []byte(config), 0644))
+
+	c, err := LoadConfig(configFile)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"jsonschema", "description"}, c.Options)
+	assert.Equal(t, LayoutFile, c.Layout)
+	assert.True(t, c.Strict)
+	assert.Equal(t, IntStringName, c.IntString)
+	assert.Equal(t, tmpDir, c.Dir)
+	assert.Equal(t, filepath.Join(tmpDir, "tools", "templates"), c.TemplatesDir())
+	assert.Len(t, c.Packages, 2)
+
+	t.Run("ForDir", func(t *testing.T) {
+		root := c.ForDir(tmpDir)
+		assert.Equal(t, LayoutFile, root.Layout)
+		assert.True(t, root.Strict)
+		assert.Nil(t, root.Packages)
+
+		legacy := c.ForDir(filepath.Join(tmpDir, "internal", "legacy"))
+		assert.Equal(t, LayoutInline, legacy.Layout)
+		assert.False(t, legacy.Strict)
+		assert.Equal(t, []string{"jsonschema", "description"}, legacy.Options)
+
+		// Not a sub-directory pattern
+		legacySub := c.ForDir(filepath.Join(tmpDir, "internal", "legacy", "sub"))
+		assert.Equal(t, LayoutFile, legacySub.Layout)
+
+		api := c.ForDir(filepath.Join(tmpDir, "api"))
+		assert.Equal(t, []string{"slog"}, api.Options)
+		apiSub := c.ForDir(filepath.Join(tmpDir, "api", "v2"))
+		assert.Equal(t, []string{"slog"}, apiSub.Options)
+		assert.True(t, apiSub.Strict)
+
+		var nilConfig *Config
+		assert.Nil(t, nilConfig.ForDir(tmpDir))
+	})
+
+	t.Run("MostSpecific", func(t *testing.T) {
+		c := &Config{
+			Dir: tmpDir,
+			Packages: map[string]PackageConfig{
+				"...":      {Layout: LayoutFile},
+				"api/...":  {IntString: IntStringName},
+				"./api/v2": {IntString: IntStringNumber},
+			},
+		}
+		assert.Equal(t, LayoutFile, c.ForDir(filepath.Join(tmpDir, "other")).Layout)
+		assert.Equal(t, IntStringName, c.ForDir(filepath.Join(tmpDir, "api", "v1")).IntString)
+		assert.Equal(t, IntStringNumber, c.ForDir(filepath.Join(tmpDir, "api", "v2")).IntString)
+		assert.Equal(t, 2*len("api"), matchPackagePattern("api/...", "api/v1"))
+		assert.Equal(t, -1, matchPackagePattern("api/...", "apiv1"))
+		assert.Equal(t, 2*len("api/v2")+1, matchPackagePattern("./api/v2/", "api/v2"))
+	})
+}
+
+func TestLoadConfig_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		errMsg string
+	}{
+		{"unknown field", "layuot: file\n", "field layuot not found"},
+		{"invalid layout", "layout: separate\n", `unknown layout "separate", expected inline or file`},
+		{"invalid intString", "intString: label\n", `unknown intString "label", expected number or name`},
+		{"invalid package layout", "packages:\n  api:\n    layout: x\n", `package api: unknown layout "x"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configFile := filepath.Join(t.TempDir(), ConfigFileName)
+			require.NoError(t, os.WriteFile(configFile, []byte(tt.config), 0644))
+			_, err := LoadConfig(configFile)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestFindConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	pkgDir := filepath.Join(tmpDir, "internal", "models")
+	require.NoError(t, os.MkdirAll(pkgDir, 0755))
+
+	// No go.mod in the parent directories of a temp dir
+	config, err := FindConfig(pkgDir)
+	require.NoError(t, err)
+	assert.Nil(t, config)
+
+	// Module root without configuration file
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module example.com/test\n"), 0644))
+	config, err = FindConfig(pkgDir)
+	require.NoError(t, err)
+	assert.Nil(t, config)
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ConfigFileName), []byte("strict: true\n"), 0644))
+	config, err = FindConfig(filepath.Join(pkgDir, "..."))
+	require.NoError(t, err)
+	require.NotNil(t, config)
+	assert.True(t, config.Strict)
+	assert.Equal(t, tmpDir, config.Dir)
+
+	// Empty configuration file
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ConfigFileName), nil, 0644))
+	config, err = FindConfig(pkgDir)
+	require.NoError(t, err)
+	require.NotNil(t, config)
+	assert.False(t, config.Strict)
+}
@@ -0,0 +1,223 @@
+package enums
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// Formats of the documentation written by WriteDoc.
+const (
+	// MarkdownFormat writes a Markdown document
+	MarkdownFormat = "md"
+	// HTMLFormat writes a static HTML page
+	HTMLFormat = "html"
+)
+
+// docType is the documentation of an enum type.
+type docType struct {
+	Package    string
+	Type       string
+	Doc        string
+	Underlying string
+	Null       string
+	Source     string
+	SourceLink string
+	HasLabels  bool
+	Values     []docValue
+}
+
+// docValue is the documentation of an enum constant.
+type docValue struct {
+	Name        string
+	Value       string
+	Label       string
+	Description string
+	Deprecated  bool
+}
+
+// docTypes returns the documentation of the enums
+// with the source links relative to sourceDir, prefixed with sourceURL.
+func docTypes(enums []*Enum, sourceDir, sourceURL string) ([]docType, error) {
+	types := make([]docType, len(enums))
+	for i, enum := range enums {
+		values, err := enum.LiteralValues()
+		if err != nil {
+			return nil, err
+		}
+		file := filepath.Join(enum.Dir, enum.File)
+		if rel, err := filepath.Rel(sourceDir, file); err == nil {
+			file = rel
+		}
+		file = filepath.ToSlash(file)
+		link := fmt.Sprintf("%s#L%d", file, enum.Line)
+		if sourceURL != "" {
+			link = strings.TrimSuffix(sourceURL, "/") + "/" + strings.TrimPrefix(link, "./")
+		}
+		types[i] = docType{
+			Package:    enum.Package,
+			Type:       enum.Type,
+			Doc:        enum.Doc,
+			Underlying: enum.Underlying,
+			Null:       enum.Null,
+			Source:     fmt.Sprintf("%s:%d", file, enum.Line),
+			SourceLink: link,
+			HasLabels:  enum.HasLabels(),
+			Values:     make([]docValue, len(enum.Enums)),
+		}
+		for j, name := range enum.Enums {
+			value := jsonLiteral(values[j])
+			if name == enum.Null {
+				value = "null"
+			}
+			types[i].Values[j] = docValue{
+				Name:        name,
+				Value:       value,
+				Label:       enum.Labels[j],
+				Description: enum.Descriptions[j],
+				Deprecated:  enum.Deprecated[j],
+			}
+		}
+	}
+	return types, nil
+}
+
+var markdownDocTemplate = template.Must(template.New("md").Funcs(template.FuncMap{
+	"cell": markdownCell,
+}).Parse(`<!-- Code generated by go-enum. DO NOT EDIT. -->
+
+# Enums
+{{range $t := .}}
+## {{.Package}}.{{.Type}}
+{{if .Doc}}
+{{.Doc}}
+{{end}}
+- Underlying type: ` + "`{{.Underlying}}`" + `
+- Nullable: {{if .Null}}yes, ` + "`{{.Null}}`" + ` is marshaled as null{{else}}no{{end}}
+- Source: [{{.Source}}]({{.SourceLink}})
+
+| Constant | Value |{{if .HasLabels}} Label |{{end}} Description |
+|----------|-------|{{if .HasLabels}}-------|{{end}}-------------|
+{{range .Values}}| {{if .Deprecated}}~~` + "`{{.Name}}`" + `~~ (deprecated){{else}}` + "`{{.Name}}`" + `{{end}} | ` + "`{{cell .Value}}`" + ` |{{if $t.HasLabels}} {{cell .Label}} |{{end}} {{cell .Description}} |
+{{end}}{{end}}`))
+
+// markdownCell escapes text for a Markdown table cell.
+func markdownCell(text string) string {
+	text = strings.ReplaceAll(text, "|", `\|`)
+	return strings.ReplaceAll(text, "\n", "<br>")
+}
+
+var htmlDocTemplate = htmltemplate.Must(htmltemplate.New("html").Funcs(htmltemplate.FuncMap{
+	"lines": func(text string) []string { return strings.Split(text, "\n") },
+}).Parse(`<!-- Code generated by go-enum. DO NOT EDIT. -->
+<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Enums</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+.deprecated code { text-decoration: line-through; }
+</style>
+</head>
+<body>
+<h1>Enums</h1>
+{{range $t := .}}
+<section id="{{.Package}}.{{.Type}}">
+<h2>{{.Package}}.{{.Type}}</h2>
+{{if .Doc}}<p>{{range $i, $line := lines .Doc}}{{if $i}}<br>{{end}}{{$line}}{{end}}</p>
+{{end}}<ul>
+<li>Underlying type: <code>{{.Underlying}}</code></li>
+<li>Nullable: {{if .Null}}yes, <code>{{.Null}}</code> is marshaled as null{{else}}no{{end}}</li>
+<li>Source: <a href="{{.SourceLink}}">{{.Source}}</a></li>
+</ul>
+<table>
+<tr><th>Constant</th><th>Value</th>{{if .HasLabels}}<th>Label</th>{{end}}<th>Description</th></tr>
+{{range .Values}}<tr{{if .Deprecated}} class="deprecated"{{end}}><td><code>{{.Name}}</code>{{if .Deprecated}} (deprecated){{end}}</td><td><code>{{.Value}}</code></td>{{if $t.HasLabels}}<td>{{.Label}}</td>{{end}}<td>{{range $i, $line := lines .Description}}{{if $i}}<br>{{end}}{{$line}}{{end}}</td></tr>
+{{end}}</table>
+</section>
+{{end}}
+</body>
+</html>
+`))
+
+// Documentation returns a document describing the passed enums
+// in the given format, either MarkdownFormat or HTMLFormat.
+//
+// Every enum type gets a section with its doc comment, underlying type,
+// nullability, a link to its source, and a table of the constants
+// with their values as marshaled to JSON, labels, descriptions,
+// and deprecation. The file paths of the source links are relative
+// to sourceDir and prefixed with sourceURL if it is not empty.
+func Documentation(enums []*Enum, format, sourceDir, sourceURL string) ([]byte, error) {
+	types, err := docTypes(enums, sourceDir, sourceURL)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	switch format {
+	case MarkdownFormat:
+		err = markdownDocTemplate.Execute(&b, types)
+	case HTMLFormat:
+		err = htmlDocTemplate.Execute(&b, types)
+	default:
+		return nil, fmt.Errorf("unknown documentation format %q, expected %q or %q", format, MarkdownFormat, HTMLFormat)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// WriteDoc writes Markdown or HTML documentation
+// of the enums found at path, see Documentation.
+//
+// Without sourceURL the source links are relative to the directory
+// of outFile. With sourceURL they are relative to the directory of path
+// and prefixed with sourceURL, which should be the URL of that directory
+// in a repository browser like https://github.com/org/repo/blob/main/.
+//
+// Parameters:
+//   - path: Directory or file path to process, may end with "..." to recurse
+//   - outFile: File for the generated document
+//   - format: MarkdownFormat or HTMLFormat
+//   - sourceURL: URL prefix for the source links (empty for relative links)
+//   - verboseOut: Writer for verbose progress output (nil to disable)
+//   - resultOut: Writer for generated document output (nil to write to outFile)
+//   - validate: If true, only check that outFile is up to date, an outdated file is returned as Result.Findings
+func WriteDoc(path, outFile, format, sourceURL string, verboseOut, resultOut io.Writer, validate bool) (*Result, error) {
+	if outFile == "" && (resultOut == nil || validate) {
+		return nil, errors.New("no output file for documentation")
+	}
+	enums, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	sourceDir, err := filepath.Abs(strings.TrimSuffix(path, "..."))
+	if err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(sourceDir); err == nil && !info.IsDir() {
+		sourceDir = filepath.Dir(sourceDir)
+	}
+	if sourceURL == "" && outFile != "" {
+		absOutFile, err := filepath.Abs(outFile)
+		if err != nil {
+			return nil, err
+		}
+		sourceDir = filepath.Dir(absOutFile)
+	}
+	doc, err := Documentation(enums, format, sourceDir, sourceURL)
+	if err != nil {
+		return nil, err
+	}
+	return writeArtifacts(map[string][]byte{outFile: doc}, verboseOut, resultOut, validate)
+}
@@ -0,0 +1,103 @@
+package enums
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const docTestSource = `package models
+
+// Status of an order.
+type Status string //#enum
+
+const (
+	// StatusActive orders are processed
+	StatusActive Status = "active" //#label:"Active"
+	// Deprecated: use StatusActive
+	StatusOpen Status = "open|legacy"
+)
+
+type Priority int //#enum
+
+const (
+	PriorityNull Priority = 0 //#null
+	PriorityHigh Priority = 1 // Handled first
+)
+`
+
+func TestDocumentation_Markdown(t *testing.T) {
+	fset, pkg, astFile := parseSource(t, docTestSource)
+	enums, err := Find(fset, pkg, astFile)
+	require.NoError(t, err)
+	enums["Status"].Dir = "/src/models"
+	enums["Priority"].Dir = "/src/models"
+
+	doc, err := Documentation([]*Enum{enums["Status"], enums["Priority"]}, MarkdownFormat, "/src", "")
+	require.NoError(t, err)
+	assert.Equal(t, "<!-- Code generated by go-enum. DO NOT EDIT. -->\n"+
+		"\n"+
+		"# Enums\n"+
+		"\n"+
+		"## models.Status\n"+
+		"\n"+
+		"Status of an order.\n"+
+		"\n"+
+		"- Underlying type: `string`\n"+
+		"- Nullable: no\n"+
+		"- Source: [models/test.go:4](models/test.go#L4)\n"+
+		"\n"+
+		"| Constant | Value | Label | Description |\n"+
+		"|----------|-------|-------|-------------|\n"+
+		"| `StatusActive` | `\"active\"` | Active | StatusActive orders are processed |\n"+
+		"| ~~`StatusOpen`~~ (deprecated) | `\"open\\|legacy\"` |  | Deprecated: use StatusActive |\n"+
+		"\n"+
+		"## models.Priority\n"+
+		"\n"+
+		"- Underlying type: `int`\n"+
+		"- Nullable: yes, `PriorityNull` is marshaled as null\n"+
+		"- Source: [models/test.go:13](models/test.go#L13)\n"+
+		"\n"+
+		"| Constant | Value | Description |\n"+
+		"|----------|-------|-------------|\n"+
+		"| `PriorityNull` | `null` |  |\n"+
+		"| `PriorityHigh` | `1` | Handled first |\n",
+		string(doc))
+
+	doc, err = Documentation([]*Enum{enums["Status"]}, MarkdownFormat, "/src", "https://github.com/org/repo/blob/main/")
+	require.NoError(t, err)
+	assert.Contains(t, string(doc), "- Source: [models/test.go:4](https://github.com/org/repo/blob/main/models/test.go#L4)\n")
+
+	_, err = Documentation(nil, "pdf", "/src", "")
+	assert.EqualError(t, err, `unknown documentation format "pdf", expected "md" or "html"`)
+}
+
+func TestWriteDoc_HTML(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "models"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "models", "models.go"), []byte(docTestSource), 0644))
+	outFile := filepath.Join(tmpDir, "docs", "enums.html")
+
+	_, err := WriteDoc(tmpDir+"/...", outFile, HTMLFormat, "", nil, nil, false)
+	require.NoError(t, err)
+	doc, err := os.ReadFile(outFile)
+	require.NoError(t, err)
+	html := string(doc)
+	assert.Contains(t, html, `<section id="models.Status">`)
+	assert.Contains(t, html, `<li>Source: <a href="../models/models.go#L4">../models/models.go:4</a></li>`)
+	assert.Contains(t, html, `<tr class="deprecated"><td><code>StatusOpen</code> (deprecated)</td><td><code>&#34;open|legacy&#34;</code></td><td></td><td>Deprecated: use StatusActive</td></tr>`)
+	assert.Contains(t, html, `<li>Nullable: yes, <code>PriorityNull</code> is marshaled as null</li>`)
+
+	// Deterministic output is up to date
+	_, err = WriteDoc(tmpDir+"/...", outFile, HTMLFormat, "", nil, nil, true)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	_, err = WriteDoc(tmpDir+"/...", "", MarkdownFormat, "", nil, &out, false)
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "- Source: [models/models.go:4](models/models.go#L4)\n")
+}
@@ -5,14 +5,22 @@ It scans Go source files for types marked with the //#enum comment,
 extracts all related information including enum values, methods,
 and configuration flags, then generates type-safe validation and utility methods.
 
-The main entry point is Rewrite, which processes Go source files and generates
+The main entry point is Run, which processes Go source files and generates
 enum methods by finding enum types (via Find), applying code generation templates,
 and updating the source files with the generated methods.
+Rewrite and ValidateRewrite are wrappers of Run for the go-enum command.
 */
 package enums
 
 import (
+	"encoding/json"
+	"fmt"
 	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"slices"
+	"strconv"
 	"strings"
 )
 
@@ -23,10 +31,17 @@ type Enum struct {
 	File string
 	// Line is the line number where the enum type is declared
 	Line int
+	// Dir is the directory of the package source files
+	Dir string
 	// Package is the package name
 	Package string
+	// ImportPath is the import path of the package,
+	// only set by Load for packages in a Go module
+	ImportPath string
 	// Type is the enum type name
 	Type string
+	// Doc is the doc comment of the enum type
+	Doc string
 	// Underlying is the underlying type (e.g., "string", "int")
 	Underlying string
 	// Recv is the method receiver name (auto-generated or from existing methods)
@@ -35,21 +50,103 @@ type Enum struct {
 	Enums []string
 	// Literals is the list of enum constant values as strings
 	Literals []string
+	// Descriptions are the doc comments of the enum constants,
+	// empty strings for undocumented constants
+	Descriptions []string
+	// Aliases are the alternative spellings of the enum constants
+	// from //#alias:"...","..." markers accepted when decoding
+	Aliases [][]string
+	// Deprecated indicates per enum constant if it is deprecated
+	// by a "Deprecated: " doc paragraph or a //#deprecated marker
+	Deprecated []bool
+	// Labels are the human readable labels of the enum constants
+	// from //#label:"..." markers, empty strings for constants without label
+	Labels []string
+	// ValueLines are the line numbers of the enum constants in File
+	ValueLines []int
 	// JSONSchemaEnum is the list of values for JSON Schema enum field
 	JSONSchemaEnum []string
 	// Null is the name of the null enum value (if //#null is used)
 	Null string
 	// JSONSchema indicates if ,jsonschema flag was set
 	JSONSchema bool
+	// Registry indicates if ,registry flag was set to generate an init
+	// function registering the type with the enumreg package
+	Registry bool
+	// Templates are the names of user-defined templates
+	// set with the ,template=... flag, see Options.Templates
+	Templates []string
+	// Slog indicates if ,slog flag was set to generate
+	// a LogValue method for the log/slog package
+	Slog bool
+	// Flag indicates if ,flag flag was set to generate the flag.Value
+	// methods and a <Type>Var function for the standard flag package
+	Flag bool
+	// PFlag indicates if ,pflag flag was set to generate the pflag.Value
+	// methods, a <Type>VarP function and a Complete<Type> function
+	// for the github.com/spf13/pflag package
+	PFlag bool
+	// PGX indicates if ,pgx flag was set to generate the pgtype scanner
+	// and valuer methods and a Register<Type>PgType function
+	// for the github.com/jackc/pgx/v5 package
+	PGX bool
+	// Assert indicates if ,assert flag was set to generate a compile-time
+	// assertion that the type implements the generic enum.Enum interface
+	Assert bool
+	// OnDeprecated indicates if ,ondeprecated flag was set to generate
+	// a hook variable called by the decoders for deprecated values
+	OnDeprecated bool
+	// Description indicates if ,description flag was set
+	// to generate a Description method returning the Descriptions
+	Description bool
+	// Unknown is the name of the enum value that unrecognized
+	// input is decoded as (if //#unknown is used)
+	Unknown string
+	// KeepUnknown indicates that unrecognized input is decoded
+	// as is instead of as the Unknown value (//#unknown:keep)
+	KeepUnknown bool
+	// Default is the name of the default enum value (if //#default is used)
+	Default string
+	// DefaultOnEmpty indicates that an empty string is
+	// decoded as the Default value (//#default:empty)
+	DefaultOnEmpty bool
+	// Normalize are the normalization modes applied to strings
+	// before parsing them, set by the ,nocase and ,normalize=... flags
+	Normalize []string
+	// Strict indicates if ,strict flag was set or strict decoding
+	// is configured to generate decoders returning an error
+	// for invalid values (unless there is an Unknown value)
+	Strict bool
+	// IntString is the strategy for the String method of integer enums,
+	// IntStringNumber or IntStringName, set by the ,string=... flag
+	// or the configuration. Empty means IntStringNumber.
+	IntString string
+	// I18N indicates if ,i18n flag was set to generate a LabelFor method
+	// translating the labels with golang.org/x/text/message
+	I18N bool
 
 	// LastEnumDecl is the AST declaration of the last enum const
 	LastEnumDecl ast.Decl
 	// KnownMethods are existing enum methods that will be replaced
 	KnownMethods []*ast.FuncDecl
+	// KnownVars are existing generated variable declarations that will be replaced
+	KnownVars []*ast.GenDecl
 	// CustomMethods names methods marked `//#custom` in their doc comment.
 	// These are hand-written and must not be regenerated or replaced.
 	// Keyed by the generator's method name (e.g. "UnmarshalJSON").
 	CustomMethods map[string]bool
+
+	// templates are the user-defined templates available
+	// when the enum was found
+	templates Templates
+	// hasOtherString indicates a String method
+	// declared in another file of the package
+	hasOtherString bool
+	// values are the type-checked values of Enums,
+	// nil for values that could not be evaluated
+	values []constant.Value
+	// userDecls are the declarations rendered by the Templates
+	userDecls []methodTemplate
 }
 
 // IsStringType returns true if the underlying type is string.
@@ -64,11 +161,395 @@ func (e *Enum) IsIntType() bool {
 		strings.HasPrefix(e.Underlying, "uint")
 }
 
+// IsUnsignedType returns true if the underlying type is an unsigned integer.
+func (e *Enum) IsUnsignedType() bool {
+	return e.Underlying == "byte" || strings.HasPrefix(e.Underlying, "uint")
+}
+
+// BitSize returns the bit size of the underlying integer type
+// as expected by strconv.ParseInt and strconv.ParseUint,
+// 0 for int and uint.
+func (e *Enum) BitSize() int {
+	if e.Underlying == "byte" {
+		return 8
+	}
+	bits, _ := strconv.Atoi(strings.TrimPrefix(strings.TrimPrefix(e.Underlying, "u"), "int"))
+	return bits
+}
+
 // IsNullable returns true if the enum has a null value defined.
 func (e *Enum) IsNullable() bool {
 	return e.Null != ""
 }
 
+// HasDescriptions returns true if any enum constant has a description.
+func (e *Enum) HasDescriptions() bool {
+	for _, description := range e.Descriptions {
+		if description != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// JSONSchemaDescriptions returns the descriptions
+// of the values in JSONSchemaEnum.
+func (e *Enum) JSONSchemaDescriptions() []string {
+	var descriptions []string
+	for i, name := range e.Enums {
+		if name != e.Null {
+			descriptions = append(descriptions, e.Descriptions[i])
+		}
+	}
+	return descriptions
+}
+
+// HasDeprecated returns true if any enum constant is deprecated.
+func (e *Enum) HasDeprecated() bool {
+	return slices.Contains(e.Deprecated, true)
+}
+
+// StrictParser returns true if the Parse<Type> function returns
+// an error for unrecognized strings, which is the case for enums
+// with strict decoding and without an unknown value.
+// Otherwise unrecognized strings are returned as is
+// or as the Unknown value like the other decoders do.
+func (e *Enum) StrictParser() bool {
+	return e.HasParser() && e.Strict && e.Unknown == ""
+}
+
+// CallsOnDeprecated returns true if the generated decoding methods
+// call the OnDeprecated<Type> hook, which requires the ,ondeprecated flag
+// and at least one deprecated constant.
+func (e *Enum) CallsOnDeprecated() bool {
+	return e.OnDeprecated && e.HasDeprecated()
+}
+
+// CurrentEnums returns the names of the enum constants that are not deprecated.
+func (e *Enum) CurrentEnums() []string {
+	var names []string
+	for i, name := range e.Enums {
+		if !e.Deprecated[i] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// EnumStringLiterals returns the Go expressions of the strings
+// returned by the generated EnumStrings method for the enum constants
+// that are not deprecated: the string literals of string enums,
+// the quoted constant names with ,string=name, and the quoted
+// type-checked values of integer enums. Values that could not be
+// evaluated are converted at runtime, see EnumStringsImports.
+func (e *Enum) EnumStringLiterals() []string {
+	var literals []string
+	for i, name := range e.Enums {
+		if e.Deprecated[i] {
+			continue
+		}
+		literal := e.Literals[i]
+		value := e.literalValue(i)
+		switch {
+		case e.IsStringType() && (strings.HasPrefix(literal, `"`) || strings.HasPrefix(literal, "`")):
+			// Keep the string literal as written
+		case e.IsStringType() && value != nil && value.Kind() == constant.String:
+			literal = strconv.Quote(constant.StringVal(value))
+		case e.IsStringType():
+			literal = "string(" + name + ")"
+		case e.IntString == IntStringName:
+			literal = strconv.Quote(name)
+		case !e.IsIntType():
+			literal = strconv.Quote(literal)
+		case value != nil && value.Kind() == constant.Int:
+			literal = strconv.Quote(value.ExactString())
+		case e.IsUnsignedType():
+			literal = "strconv.FormatUint(uint64(" + name + "), 10)"
+		default:
+			literal = "strconv.FormatInt(int64(" + name + "), 10)"
+		}
+		literals = append(literals, literal)
+	}
+	return literals
+}
+
+// EnumStringsImports returns the imports needed by the
+// EnumStringLiterals of the generated EnumStrings method.
+func (e *Enum) EnumStringsImports() []string {
+	for _, literal := range e.EnumStringLiterals() {
+		if strings.HasPrefix(literal, "strconv.") {
+			return []string{`"strconv"`}
+		}
+	}
+	return nil
+}
+
+// DeprecatedEnums returns the names of the deprecated enum constants.
+func (e *Enum) DeprecatedEnums() []string {
+	var names []string
+	for i, name := range e.Enums {
+		if e.Deprecated[i] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// JSONSchemaDeprecated returns if the enum constants are deprecated
+// in the order of JSONSchemaEnum.
+func (e *Enum) JSONSchemaDeprecated() []bool {
+	var deprecated []bool
+	for i, name := range e.Enums {
+		if name != e.Null {
+			deprecated = append(deprecated, e.Deprecated[i])
+		}
+	}
+	return deprecated
+}
+
+// JSONSchemaOneOf returns true if the JSON Schema of the enum describes
+// the values additionally as oneOf const schemas to annotate them
+// with descriptions or as deprecated. Used for the generated JSONSchema
+// method and the standalone export.
+func (e *Enum) JSONSchemaOneOf() bool {
+	return e.HasDescriptions() || e.HasDeprecated()
+}
+
+// HasLabels returns true if any enum constant has a //#label marker.
+func (e *Enum) HasLabels() bool {
+	for _, label := range e.Labels {
+		if label != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// DisplayLabels returns the Labels with the string value
+// of string enums or the constant name of other enums
+// for constants without label.
+func (e *Enum) DisplayLabels() []string {
+	labels := make([]string, len(e.Enums))
+	for i, label := range e.Labels {
+		switch {
+		case label != "":
+			labels[i] = label
+		case e.IsStringType():
+			if str, err := strconv.Unquote(e.Literals[i]); err == nil {
+				labels[i] = str
+				break
+			}
+			fallthrough
+		default:
+			labels[i] = e.Enums[i]
+		}
+	}
+	return labels
+}
+
+// HasCatalog returns true if the labels of the enum belong
+// into a message catalog, which is the case for enums
+// with the ,i18n flag or //#label markers.
+func (e *Enum) HasCatalog() bool {
+	return e.I18N || e.HasLabels()
+}
+
+// MessageLabels returns the DisplayLabels escaped for
+// the use as golang.org/x/text/message format strings.
+func (e *Enum) MessageLabels() []string {
+	labels := e.DisplayLabels()
+	for i, label := range labels {
+		labels[i] = strings.ReplaceAll(label, "%", "%%")
+	}
+	return labels
+}
+
+// HasAliases returns true if any enum constant has an //#alias marker.
+func (e *Enum) HasAliases() bool {
+	for _, aliases := range e.Aliases {
+		if len(aliases) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// HasParser returns true if a Parse<Type> function is generated
+// that is used by all generated decoding methods.
+// This is the case for string enums with aliases, normalization,
+// an unknown value, a default value for empty strings,
+// a hook for deprecated values, or strict decoding.
+func (e *Enum) HasParser() bool {
+	return e.IsStringType() && (e.HasAliases() || len(e.Normalize) > 0 || e.Unknown != "" || e.DefaultOnEmpty || e.CallsOnDeprecated() || e.Strict)
+}
+
+// SchemaDefault returns the name of the enum value used as default
+// by the generated JSONSchema method: the //#default value,
+// or the //#null value of a nullable enum without default.
+func (e *Enum) SchemaDefault() string {
+	if e.Default != "" {
+		return e.Default
+	}
+	return e.Null
+}
+
+// Normalization modes for parsing string enums
+// set with the ,normalize=... flag.
+const (
+	// NormalizeTrim removes leading and trailing white space
+	NormalizeTrim = "trim"
+	// NormalizeLower converts to lower case, also set by the ,nocase flag
+	NormalizeLower = "lower"
+	// NormalizeSnake converts to lower case and replaces spaces and hyphens with underscores
+	NormalizeSnake = "snake"
+)
+
+// Normalizes returns true if the normalization mode is set.
+func (e *Enum) Normalizes(mode string) bool {
+	return slices.Contains(e.Normalize, mode)
+}
+
+// normalize applies the normalization modes to s
+// like the expression returned by ParseKey at runtime.
+func (e *Enum) normalize(s string) string {
+	if e.Normalizes(NormalizeTrim) {
+		s = strings.TrimSpace(s)
+	}
+	if e.Normalizes(NormalizeLower) || e.Normalizes(NormalizeSnake) {
+		s = strings.ToLower(s)
+	}
+	if e.Normalizes(NormalizeSnake) {
+		s = strings.ReplaceAll(strings.ReplaceAll(s, " ", "_"), "-", "_")
+	}
+	return s
+}
+
+// ParseKey returns the Go expression normalizing the
+// argument s of the generated Parse<Type> function.
+func (e *Enum) ParseKey() string {
+	return e.normalizeExpr("s")
+}
+
+// FlagParseKey returns the Go expression normalizing the
+// argument str of the generated flag.Value Set method like ParseKey.
+func (e *Enum) FlagParseKey() string {
+	return e.normalizeExpr("str")
+}
+
+// normalizeExpr returns the Go expression normalizing the variable key.
+func (e *Enum) normalizeExpr(key string) string {
+	if e.Normalizes(NormalizeTrim) {
+		key = "strings.TrimSpace(" + key + ")"
+	}
+	if e.Normalizes(NormalizeLower) || e.Normalizes(NormalizeSnake) {
+		key = "strings.ToLower(" + key + ")"
+	}
+	if e.Normalizes(NormalizeSnake) {
+		key = `strings.ReplaceAll(strings.ReplaceAll(` + key + `, " ", "_"), "-", "_")`
+	}
+	return key
+}
+
+// parseCase is a case of the switch statement in the generated Parse<Type> function.
+type parseCase struct {
+	// Const is the name of the enum constant
+	Const string
+	// Inputs are the quoted strings parsed as Const
+	Inputs []string
+	// Deprecated is true if Const is deprecated
+	Deprecated bool
+}
+
+// parseInputs returns the normalized strings accepted by the generated
+// Parse<Type> function for every enum constant, its value and aliases,
+// or an error if the inputs of different constants collide.
+func (e *Enum) parseInputs() ([][]string, error) {
+	values, err := e.LiteralValues()
+	if err != nil {
+		return nil, err
+	}
+	inputs := make([][]string, len(e.Enums))
+	seen := make(map[string]int) // normalized input -> enum index
+	add := func(index int, kind, input string) error {
+		key := e.normalize(input)
+		if other, exists := seen[key]; exists {
+			if other == index {
+				return nil // Same constant, no duplicate switch case
+			}
+			if key != input {
+				return fmt.Errorf("%s %q of %s for type %s.%s in %s:%d collides with %s after normalization to %q",
+					kind, input, e.Enums[index], e.Package, e.Type, e.File, e.Line, e.Enums[other], key)
+			}
+			return fmt.Errorf("%s %q of %s for type %s.%s in %s:%d collides with %s",
+				kind, input, e.Enums[index], e.Package, e.Type, e.File, e.Line, e.Enums[other])
+		}
+		seen[key] = index
+		inputs[index] = append(inputs[index], key)
+		return nil
+	}
+	for i, value := range values {
+		if err := add(i, "value", fmt.Sprint(value)); err != nil {
+			return nil, err
+		}
+	}
+	for i, aliases := range e.Aliases {
+		for _, alias := range aliases {
+			if err := add(i, "alias", alias); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if e.DefaultOnEmpty {
+		if err := add(slices.Index(e.Enums, e.Default), "empty input", ""); err != nil {
+			return nil, err
+		}
+	}
+	return inputs, nil
+}
+
+// ParseCases returns the cases of the generated Parse<Type> function
+// with the normalized value and aliases of every enum constant as inputs.
+func (e *Enum) ParseCases() ([]parseCase, error) {
+	inputs, err := e.parseInputs()
+	if err != nil {
+		return nil, err
+	}
+	cases := make([]parseCase, len(e.Enums))
+	for i, name := range e.Enums {
+		cases[i].Const = name
+		cases[i].Deprecated = e.Deprecated[i]
+		for _, input := range inputs[i] {
+			cases[i].Inputs = append(cases[i].Inputs, strconv.Quote(input))
+		}
+	}
+	return cases, nil
+}
+
+// UnknownInputs returns the quoted normalized inputs parsed as
+// the Unknown constant by the generated Parse<Type> function,
+// that is its value and aliases without the unrecognized strings
+// mapped to it.
+func (e *Enum) UnknownInputs() ([]string, error) {
+	cases, err := e.ParseCases()
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range cases {
+		if c.Const == e.Unknown {
+			return c.Inputs, nil
+		}
+	}
+	return nil, nil
+}
+
+// ZeroValue returns the literal of the zero value of the underlying type.
+func (e *Enum) ZeroValue() string {
+	if e.IsStringType() {
+		return `""`
+	}
+	return "0"
+}
+
 // LastIndex returns the index of the last enum value.
 func (e *Enum) LastIndex() int {
 	return len(e.Enums) - 1
@@ -91,3 +572,66 @@ func (e *Enum) JSONType() string {
 		return "string"
 	}
 }
+
+// literalValue returns the type-checked value of the enum constant
+// at index i, or the value of its literal evaluated without a scope
+// if the package could not be type-checked.
+func (e *Enum) literalValue(i int) constant.Value {
+	if i < len(e.values) && e.values[i] != nil {
+		return e.values[i]
+	}
+	tv, err := types.Eval(token.NewFileSet(), nil, token.NoPos, e.Literals[i])
+	if err != nil {
+		return nil
+	}
+	return tv.Value
+}
+
+// LiteralValues returns the evaluated constant values of Literals
+// as string for string enums and as int64 or uint64 for integer enums.
+//
+// Values using iota, other constants or conversions are only available
+// for enums returned by Find or Load, which type-check the package.
+func (e *Enum) LiteralValues() ([]any, error) {
+	values := make([]any, len(e.Literals))
+	for i, literal := range e.Literals {
+		value := e.literalValue(i)
+		if value == nil || value.Kind() == constant.Unknown {
+			return nil, fmt.Errorf("can't evaluate value %s of enum %s.%s", literal, e.Package, e.Type)
+		}
+		switch value.Kind() {
+		case constant.String:
+			values[i] = constant.StringVal(value)
+		case constant.Int:
+			if v, exact := constant.Int64Val(value); exact {
+				values[i] = v
+			} else if v, exact := constant.Uint64Val(value); exact {
+				values[i] = v
+			} else {
+				return nil, fmt.Errorf("value %s of enum %s.%s overflows 64 bits", literal, e.Package, e.Type)
+			}
+		default:
+			return nil, fmt.Errorf("value %s of enum %s.%s is not a string or integer constant", literal, e.Package, e.Type)
+		}
+	}
+	return values, nil
+}
+
+// jsonLiteral returns the JSON representation of a value
+// returned by Enum.LiteralValues.
+func jsonLiteral(value any) string {
+	switch v := value.(type) {
+	case string:
+		var b strings.Builder
+		enc := json.NewEncoder(&b)
+		enc.SetEscapeHTML(false)
+		_ = enc.Encode(v) // can't fail for a string
+		return strings.TrimSuffix(b.String(), "\n")
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	default:
+		panic(fmt.Sprintf("unsupported enum value type %T", value))
+	}
+}
@@ -3,8 +3,13 @@ package enums
 import (
 	"fmt"
 	"go/ast"
+	"go/constant"
 	"go/token"
+	"go/types"
+	"maps"
+	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/ungerik/go-astvisit"
@@ -19,6 +24,12 @@ import (
 // Returns a map of enum type name to Enum metadata, or an error if the enum
 // definitions are invalid.
 func Find(fset *token.FileSet, pkg *ast.Package, astFile *ast.File) (map[string]*Enum, error) {
+	return find(fset, pkg, astFile, nil, nil)
+}
+
+// find is Find with the Config for the package of astFile applied,
+// config may be nil.
+func find(fset *token.FileSet, pkg *ast.Package, astFile *ast.File, config *Config, templates Templates) (map[string]*Enum, error) {
 	// Validate package name
 	if pkg == nil || pkg.Name == "" {
 		return nil, fmt.Errorf("invalid or missing package name in %s", astFile.Name.Name)
@@ -36,6 +47,11 @@ func Find(fset *token.FileSet, pkg *ast.Package, astFile *ast.File) (map[string]
 			if !ok || typeSpec.Comment == nil {
 				continue
 			}
+			doc := typeSpec.Doc
+			if doc == nil && !genDecl.Lparen.IsValid() {
+				// Doc comment of a single type declaration without parentheses
+				doc = genDecl.Doc
+			}
 			for _, c := range typeSpec.Comment.List {
 				parts := strings.Split(c.Text, ",")
 				for i, part := range parts {
@@ -46,15 +62,26 @@ func Find(fset *token.FileSet, pkg *ast.Package, astFile *ast.File) (map[string]
 					if typeName == "" {
 						return nil, fmt.Errorf("enum type has empty name in %s:%d", astFile.Name.Name, fset.Position(typeSpec.Pos()).Line)
 					}
-					enums[typeName] = &Enum{
-						File:          astFile.Name.Name,
-						Line:          fset.Position(typeSpec.Pos()).Line,
+					pos := fset.Position(typeSpec.Pos())
+					enum := &Enum{
+						File:          filepath.Base(pos.Filename),
+						Line:          pos.Line,
+						Dir:           filepath.Dir(pos.Filename),
 						Package:       pkg.Name,
 						Type:          typeName,
+						Doc:           docText(doc),
 						Underlying:    astvisit.ExprString(typeSpec.Type),
-						JSONSchema:    slices.Contains(parts, "jsonschema"),
 						CustomMethods: make(map[string]bool),
 					}
+					if err := enum.applyConfig(config); err != nil {
+						return nil, fmt.Errorf("enum type %s.%s in %s:%d: %s: %w", enum.Package, enum.Type, enum.File, enum.Line, ConfigFileName, err)
+					}
+					for _, option := range parts[1:] {
+						if err := enum.setOption(option); err != nil {
+							return nil, fmt.Errorf("enum type %s.%s in %s:%d: %w", enum.Package, enum.Type, enum.File, enum.Line, err)
+						}
+					}
+					enums[typeName] = enum
 					break
 				}
 			}
@@ -82,12 +109,22 @@ func Find(fset *token.FileSet, pkg *ast.Package, astFile *ast.File) (map[string]
 				continue
 			}
 			enum.LastEnumDecl = decl
+			doc := valueSpec.Doc
+			if doc == nil && !genDecl.Lparen.IsValid() {
+				// Doc comment of a single const declaration without parentheses
+				doc = genDecl.Doc
+			}
+			markers, err := parseMarkers(doc, valueSpec.Comment)
+			if err != nil {
+				return nil, fmt.Errorf("enum value %s: %w", valueSpec.Names[0].Name, err)
+			}
 			isNullValue := false
-			if valueSpec.Comment != nil {
-				for _, c := range valueSpec.Comment.List {
-					if c.Text != "//#null" {
-						continue
-					}
+			deprecated := false
+			label := ""
+			var aliases []string
+			for _, m := range markers {
+				switch m.name {
+				case "null":
 					if enum.Null != "" {
 						return nil, fmt.Errorf("second //#null enum encountered %s", valueSpec.Names[0].Name)
 					}
@@ -96,12 +133,83 @@ func Find(fset *token.FileSet, pkg *ast.Package, astFile *ast.File) (map[string]
 					}
 					enum.Null = valueSpec.Names[0].Name
 					isNullValue = true
-					break
+				case "unknown":
+					if enum.Unknown != "" {
+						return nil, fmt.Errorf("second //#unknown enum encountered %s", valueSpec.Names[0].Name)
+					}
+					if len(valueSpec.Names) > 1 {
+						return nil, fmt.Errorf("cant use //#unknown for multiple enums: %#v", valueSpec.Names)
+					}
+					switch m.args {
+					case "":
+					case "keep":
+						enum.KeepUnknown = true
+					default:
+						return nil, fmt.Errorf("//#unknown of %s has invalid argument %q, expected none or keep", valueSpec.Names[0].Name, m.args)
+					}
+					enum.Unknown = valueSpec.Names[0].Name
+				case "default":
+					if enum.Default != "" {
+						return nil, fmt.Errorf("second //#default enum encountered %s", valueSpec.Names[0].Name)
+					}
+					if len(valueSpec.Names) > 1 {
+						return nil, fmt.Errorf("cant use //#default for multiple enums: %#v", valueSpec.Names)
+					}
+					switch m.args {
+					case "":
+					case "empty":
+						if !enum.IsStringType() {
+							return nil, fmt.Errorf("//#default:empty of %s is only supported for string enums", valueSpec.Names[0].Name)
+						}
+						enum.DefaultOnEmpty = true
+					default:
+						return nil, fmt.Errorf("//#default of %s has invalid argument %q, expected none or empty", valueSpec.Names[0].Name, m.args)
+					}
+					enum.Default = valueSpec.Names[0].Name
+				case "deprecated":
+					deprecated = true
+				case "label":
+					if len(valueSpec.Names) > 1 {
+						return nil, fmt.Errorf("cant use //#label for multiple enums: %#v", valueSpec.Names)
+					}
+					labels, err := m.strings()
+					if err != nil || len(labels) != 1 {
+						return nil, fmt.Errorf("//#label of %s needs one quoted string, got: %s", valueSpec.Names[0].Name, m.args)
+					}
+					label = labels[0]
+				case "alias":
+					if len(valueSpec.Names) > 1 {
+						return nil, fmt.Errorf("cant use //#alias for multiple enums: %#v", valueSpec.Names)
+					}
+					if !enum.IsStringType() {
+						return nil, fmt.Errorf("//#alias of %s is only supported for string enums", valueSpec.Names[0].Name)
+					}
+					strs, err := m.strings()
+					if err != nil || len(strs) == 0 {
+						return nil, fmt.Errorf("//#alias of %s needs comma separated quoted strings, got: %s", valueSpec.Names[0].Name, m.args)
+					}
+					aliases = append(aliases, strs...)
 				}
 			}
+			if enum.Unknown != "" && enum.Unknown == enum.Null {
+				return nil, fmt.Errorf("//#unknown enum %s can't also be the //#null enum", enum.Unknown)
+			}
+			if enum.Default != "" && enum.Default == enum.Null {
+				return nil, fmt.Errorf("//#default enum %s can't also be the //#null enum", enum.Default)
+			}
 			for i, name := range valueSpec.Names {
 				enum.Enums = append(enum.Enums, name.Name)
 				enum.Literals = append(enum.Literals, astvisit.ExprString(valueSpec.Values[i]))
+				enum.Labels = append(enum.Labels, label)
+				enum.ValueLines = append(enum.ValueLines, fset.Position(name.Pos()).Line)
+				enum.Aliases = append(enum.Aliases, aliases)
+				description := docText(doc)
+				if doc == nil {
+					// Use a trailing comment like "StatusActive Status = "active" // Active orders"
+					description = docText(valueSpec.Comment)
+				}
+				enum.Descriptions = append(enum.Descriptions, description)
+				enum.Deprecated = append(enum.Deprecated, deprecated || isDeprecatedDoc(description))
 				// Only add non-null values to JSONSchemaEnum because null is another oneOf type variant
 				if !isNullValue {
 					if enum.Underlying == "string" || enum.Underlying == "int" {
@@ -115,6 +223,8 @@ func Find(fset *token.FileSet, pkg *ast.Package, astFile *ast.File) (map[string]
 		}
 	}
 
+	setConstValues(fset, pkg, astFile, enums)
+
 	for _, enum := range enums {
 		if len(enum.Enums) == 0 {
 			return nil, fmt.Errorf("enum type %s.%s in %s:%d has no typed const enum values", enum.Package, enum.Type, enum.File, enum.Line)
@@ -133,79 +243,376 @@ func Find(fset *token.FileSet, pkg *ast.Package, astFile *ast.File) (map[string]
 		// Check for duplicate literal values
 		seenLiterals := make(map[string]string) // literal -> first name
 		for i, literal := range enum.Literals {
-			if firstName, exists := seenLiterals[literal]; exists {
+			key := literal
+			if value := enum.literalValue(i); value != nil && value.Kind() != constant.Unknown {
+				// Compare the values, so that 1 and 0x1 are duplicates
+				key = value.ExactString()
+			}
+			if firstName, exists := seenLiterals[key]; exists {
 				return nil, fmt.Errorf("duplicate enum value %s for type %s.%s in %s:%d (used by both %s and %s)",
 					literal, enum.Package, enum.Type, enum.File, enum.Line, firstName, enum.Enums[i])
 			}
-			seenLiterals[literal] = enum.Enums[i]
+			seenLiterals[key] = enum.Enums[i]
+		}
+
+		// Check for values and aliases colliding after normalization
+		if enum.HasParser() {
+			if _, err := enum.parseInputs(); err != nil {
+				return nil, err
+			}
 		}
 	}
 
-	// Find known enum methods
+	markOtherStringMethods(pkg, astFile, enums)
+
+	// Use the receiver name of existing methods
 	for _, decl := range astFile.Decls {
 		funcDecl, ok := decl.(*ast.FuncDecl)
 		if !ok || funcDecl.Recv == nil {
 			continue
 		}
 		recv := funcDecl.Recv.List[0]
-		recvType := strings.TrimPrefix(astvisit.ExprString(recv.Type), "*")
-		enum, ok := enums[recvType]
+		enum, ok := enums[strings.TrimPrefix(astvisit.ExprString(recv.Type), "*")]
+		if ok && len(recv.Names) > 0 {
+			enum.Recv = recv.Names[0].Name
+		}
+	}
+
+	// Set common method receiver name
+	// if no existing method was encountered
+	for _, enum := range enums {
+		if enum.Recv == "" {
+			if len(enum.Type) == 0 {
+				// Should never happen due to earlier validation, but be defensive
+				return nil, fmt.Errorf("enum type %s.%s has empty name", enum.Package, enum.Type)
+			}
+			enum.Recv = strings.ToLower(enum.Type[:1])
+		}
+		// User-defined templates are rendered with the receiver name
+		// so that their declaration names are known
+		if err := enum.renderUserTemplates(templates); err != nil {
+			return nil, err
+		}
+	}
+
+	// Find known enum methods
+	for _, decl := range astFile.Decls {
+		if genDecl, ok := decl.(*ast.GenDecl); ok {
+			// Generated variables like OnDeprecatedStatus
+			name := varDeclName(genDecl)
+			if name == "" {
+				continue
+			}
+			for _, enum := range enums {
+				if !enum.generates(name, varKind) {
+					continue
+				}
+				if name == "_" && !isEnumAssertion(genDecl, enum.Type) {
+					// Other blank variables of the package
+					continue
+				}
+				if isCustom(genDecl.Doc) {
+					enum.CustomMethods[name] = true
+				} else {
+					enum.KnownVars = append(enum.KnownVars, genDecl)
+				}
+			}
+			continue
+		}
+		funcDecl, ok := decl.(*ast.FuncDecl)
 		if !ok {
 			continue
 		}
-		if len(recv.Names) > 0 {
-			enum.Recv = recv.Names[0].Name
+		if funcDecl.Recv == nil {
+			// Generated functions like ParseStatus
+			for _, enum := range enums {
+				if !enum.generates(funcDecl.Name.Name, funcKind) {
+					continue
+				}
+				if funcDecl.Name.Name == "init" && !isRegistryInit(funcDecl, enum.Type) {
+					// Other init functions of the package
+					continue
+				}
+				if isCustom(funcDecl.Doc) {
+					enum.CustomMethods[funcDecl.Name.Name] = true
+				} else {
+					enum.KnownMethods = append(enum.KnownMethods, funcDecl)
+				}
+			}
+			continue
+		}
+		recvType := strings.TrimPrefix(astvisit.ExprString(funcDecl.Recv.List[0].Type), "*")
+		enum, ok := enums[recvType]
+		if !ok {
+			continue
 		}
-		// The generator produces these methods. When one already exists,
+		// When a method produced by the generator already exists,
 		// route it to either CustomMethods (hand-written, must be preserved)
 		// or KnownMethods (will be replaced by the generated version).
-		generated := false
-		switch funcDecl.Name.Name {
-		case "Valid", "Validate", "Enums", "EnumStrings":
-			generated = true
-		case "String":
-			generated = enum.IsStringType()
-		case "IsNull", "IsNotNull", "SetNull", "MarshalJSON", "UnmarshalJSON", "Scan", "Value":
-			generated = enum.IsNullable()
-		case "JSONSchema":
-			generated = enum.JSONSchema
-		}
-		if !generated {
+		// A String method previously generated into this file is removed
+		// when another file of the package declares one.
+		if !enum.generates(funcDecl.Name.Name, methodKind) && !(enum.hasOtherString && funcDecl.Name.Name == "String") {
 			continue
 		}
-		if isCustom(funcDecl) {
+		if isCustom(funcDecl.Doc) {
 			enum.CustomMethods[funcDecl.Name.Name] = true
 		} else {
 			enum.KnownMethods = append(enum.KnownMethods, funcDecl)
 		}
 	}
 
-	// Set common method receiver name
-	// if no existing method was encountered
-	for _, enum := range enums {
-		if enum.Recv == "" {
-			if len(enum.Type) == 0 {
-				// Should never happen due to earlier validation, but be defensive
-				return nil, fmt.Errorf("enum type %s.%s has empty name", enum.Package, enum.Type)
+	return enums, nil
+}
+
+// applyConfig applies the options of the config
+// before the options of the type are set.
+func (e *Enum) applyConfig(config *Config) error {
+	if config == nil {
+		return nil
+	}
+	for _, option := range config.Options {
+		if !e.IsStringType() && (option == "nocase" || strings.HasPrefix(option, "normalize=")) {
+			// String enum only options are defaults for string enums
+			continue
+		}
+		if option == "pgx" && !e.IsStringType() && !e.IsIntType() {
+			continue
+		}
+		if err := e.setOption(option); err != nil {
+			return err
+		}
+	}
+	if config.Strict {
+		e.Strict = true
+	}
+	if e.IsIntType() {
+		e.IntString = config.IntString
+	}
+	return nil
+}
+
+// setOption sets an option from the comma separated
+// list after the //#enum marker of the type.
+func (e *Enum) setOption(option string) error {
+	switch option {
+	case "":
+		// Ignore empty option from trailing comma
+	case "jsonschema":
+		e.JSONSchema = true
+	case "description":
+		e.Description = true
+	case "i18n":
+		e.I18N = true
+	case "registry":
+		e.Registry = true
+	case "assert":
+		e.Assert = true
+	case "slog":
+		e.Slog = true
+	case "flag":
+		e.Flag = true
+	case "pflag":
+		e.PFlag = true
+	case "pgx":
+		if !e.IsStringType() && !e.IsIntType() {
+			return fmt.Errorf("pgx is only supported for string and integer enums, not %s", e.Underlying)
+		}
+		e.PGX = true
+	case "ondeprecated":
+		e.OnDeprecated = true
+	case "strict":
+		e.Strict = true
+	case "nocase":
+		return e.addNormalize(NormalizeLower)
+	default:
+		if names, ok := strings.CutPrefix(option, "template="); ok {
+			for name := range strings.SplitSeq(names, "|") {
+				if name == "" {
+					return fmt.Errorf("empty template name in //#enum option %q", option)
+				}
+				e.Templates = append(e.Templates, name)
+			}
+			return nil
+		}
+		if strategy, ok := strings.CutPrefix(option, "string="); ok {
+			if err := validateIntString(strategy); err != nil {
+				return err
+			}
+			if !e.IsIntType() {
+				return fmt.Errorf("string=%s is only supported for integer enums", strategy)
+			}
+			e.IntString = strategy
+			return nil
+		}
+		modes, ok := strings.CutPrefix(option, "normalize=")
+		if !ok {
+			return fmt.Errorf("unknown //#enum option %q", option)
+		}
+		for mode := range strings.SplitSeq(modes, "|") {
+			if err := e.addNormalize(mode); err != nil {
+				return err
 			}
-			enum.Recv = strings.ToLower(enum.Type[:1])
 		}
 	}
+	return nil
+}
 
-	return enums, nil
+// addNormalize adds a normalization mode for parsing string enums.
+func (e *Enum) addNormalize(mode string) error {
+	switch mode {
+	case NormalizeTrim, NormalizeLower, NormalizeSnake:
+	default:
+		return fmt.Errorf("unknown normalize mode %q, expected %s, %s or %s", mode, NormalizeTrim, NormalizeLower, NormalizeSnake)
+	}
+	if !e.IsStringType() {
+		return fmt.Errorf("normalize=%s is only supported for string enums", mode)
+	}
+	if !slices.Contains(e.Normalize, mode) {
+		e.Normalize = append(e.Normalize, mode)
+	}
+	return nil
+}
+
+// marker is a //#name or //#name:args annotation in a comment.
+type marker struct {
+	name string
+	args string
+}
+
+// strings returns the args of the marker parsed as
+// comma separated list of quoted Go strings.
+func (m marker) strings() ([]string, error) {
+	var result []string
+	args := strings.TrimSpace(m.args)
+	for args != "" {
+		quoted, err := strconv.QuotedPrefix(args)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quoted string in //#%s:%s", m.name, m.args)
+		}
+		str, err := strconv.Unquote(quoted)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, str)
+		args = strings.TrimSpace(args[len(quoted):])
+		if args != "" {
+			if args[0] != ',' {
+				return nil, fmt.Errorf("expected comma in //#%s:%s", m.name, m.args)
+			}
+			args = strings.TrimSpace(args[1:])
+		}
+	}
+	return result, nil
+}
+
+// parseMarkers returns the markers in the comments of the groups.
+// A comment line may contain multiple markers like
+// //#null //#label:"None", and gofmt-normalised forms
+// with a space after the slashes are tolerated.
+func parseMarkers(groups ...*ast.CommentGroup) ([]marker, error) {
+	var markers []marker
+	for _, group := range groups {
+		if group == nil {
+			continue
+		}
+		for _, c := range group.List {
+			text := strings.TrimSpace(strings.TrimPrefix(c.Text, "//"))
+			for strings.HasPrefix(text, "#") {
+				text = text[1:]
+				end := strings.IndexAny(text, ": \t")
+				if end == -1 {
+					end = len(text)
+				}
+				m := marker{name: text[:end]}
+				text = text[end:]
+				if strings.HasPrefix(text, ":") {
+					// Args end at the next marker that is not inside of a quoted string
+					text = text[1:]
+					end = 0
+					for end < len(text) && !strings.HasPrefix(text[end:], "//") {
+						if text[end] == '"' || text[end] == '`' {
+							quoted, err := strconv.QuotedPrefix(text[end:])
+							if err != nil {
+								return nil, fmt.Errorf("invalid quoted string in //#%s:%s", m.name, text)
+							}
+							end += len(quoted)
+							continue
+						}
+						end++
+					}
+					m.args = strings.TrimSpace(text[:end])
+					text = text[end:]
+				}
+				markers = append(markers, m)
+				text = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(text), "//"))
+			}
+		}
+	}
+	return markers, nil
+}
+
+// isRegistryInit returns true if funcDecl is an init function
+// registering the enum type with the enumreg package like
+// the one generated for the ,registry flag.
+func isRegistryInit(funcDecl *ast.FuncDecl, enumType string) bool {
+	if funcDecl.Body == nil || len(funcDecl.Body.List) != 1 {
+		return false
+	}
+	stmt, ok := funcDecl.Body.List[0].(*ast.ExprStmt)
+	if !ok {
+		return false
+	}
+	call, ok := stmt.X.(*ast.CallExpr)
+	if !ok || astvisit.ExprString(call.Fun) != "enumreg.Register" || len(call.Args) != 1 {
+		return false
+	}
+	lit, ok := call.Args[0].(*ast.CompositeLit)
+	return ok && astvisit.ExprString(lit.Type) == "enumreg.Definition["+enumType+"]"
+}
+
+// isEnumAssertion returns true if genDecl is a compile-time assertion
+// that the enum type implements the enum.Enum interface like
+// the one generated for the ,assert flag.
+func isEnumAssertion(genDecl *ast.GenDecl, enumType string) bool {
+	valueSpec := genDecl.Specs[0].(*ast.ValueSpec)
+	return valueSpec.Type != nil && astvisit.ExprString(valueSpec.Type) == "enum.Enum["+enumType+"]"
+}
+
+// varDeclName returns the name of a var declaration
+// with a single variable, or an empty string.
+func varDeclName(genDecl *ast.GenDecl) string {
+	if genDecl.Tok != token.VAR || len(genDecl.Specs) != 1 {
+		return ""
+	}
+	valueSpec, ok := genDecl.Specs[0].(*ast.ValueSpec)
+	if !ok || len(valueSpec.Names) != 1 {
+		return ""
+	}
+	return valueSpec.Names[0].Name
 }
 
-// isCustom reports whether the method's doc comment contains a `//#custom`
-// marker line. Such methods are treated as hand-written overrides and are
-// neither replaced nor regenerated.
+// isDeprecatedDoc returns true if a paragraph of the doc text
+// starts with "Deprecated: " following the Go convention.
+func isDeprecatedDoc(text string) bool {
+	for paragraph := range strings.SplitSeq(text, "\n\n") {
+		if strings.HasPrefix(paragraph, "Deprecated: ") {
+			return true
+		}
+	}
+	return false
+}
+
+// isCustom reports whether the doc comment of a method, function, or variable
+// contains a `//#custom` marker line. Such declarations are treated as
+// hand-written overrides and are neither replaced nor regenerated.
 //
 // The match tolerates gofmt-normalised forms ("// #custom" with a space
 // after the slashes) so that comments survive AST round-tripping.
-func isCustom(fn *ast.FuncDecl) bool {
-	if fn.Doc == nil {
+func isCustom(doc *ast.CommentGroup) bool {
+	if doc == nil {
 		return false
 	}
-	for _, c := range fn.Doc.List {
+	for _, c := range doc.List {
 		trimmed := strings.TrimSpace(strings.TrimPrefix(c.Text, "//"))
 		if trimmed == "#custom" {
 			return true
@@ -213,3 +620,83 @@ func isCustom(fn *ast.FuncDecl) bool {
 	}
 	return false
 }
+
+// docText returns the text of a doc comment without
+// the lines of //# markers like //#null.
+func docText(doc *ast.CommentGroup) string {
+	if doc == nil {
+		return ""
+	}
+	var lines []string
+	for _, line := range strings.Split(doc.Text(), "\n") {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// setConstValues type-checks the files of pkg and sets Enum.values
+// to the values of the enum constants declared in astFile.
+//
+// Imports are not resolved, so only constants that don't depend on
+// other packages are evaluated, all type errors are ignored.
+func setConstValues(fset *token.FileSet, pkg *ast.Package, astFile *ast.File, enums map[string]*Enum) {
+	files := []*ast.File{astFile}
+	for _, filePath := range slices.Sorted(maps.Keys(pkg.Files)) {
+		if file := pkg.Files[filePath]; file != astFile {
+			files = append(files, file)
+		}
+	}
+	config := types.Config{
+		Importer: noImporter{},
+		Error:    func(error) {}, // Continue after errors
+	}
+	checked, _ := config.Check(pkg.Name, fset, files, nil)
+	if checked == nil {
+		return
+	}
+	for _, enum := range enums {
+		enum.values = make([]constant.Value, len(enum.Enums))
+		for i, name := range enum.Enums {
+			if c, ok := checked.Scope().Lookup(name).(*types.Const); ok {
+				enum.values[i] = c.Val()
+			}
+		}
+	}
+}
+
+// noImporter is a types.Importer that doesn't import any package.
+type noImporter struct{}
+
+func (noImporter) Import(path string) (*types.Package, error) {
+	return nil, fmt.Errorf("package %s is not imported to evaluate enum values", path)
+}
+
+// markOtherStringMethods sets Enum.hasOtherString for the enums
+// with a String method in another file of the package,
+// like one generated by stringer. The generated file
+// of astFile for the LayoutFile layout is not checked.
+func markOtherStringMethods(pkg *ast.Package, astFile *ast.File, enums map[string]*Enum) {
+	var generatedFile string
+	for filePath, file := range pkg.Files {
+		if file == astFile {
+			generatedFile = strings.TrimSuffix(filePath, ".go") + GeneratedFileSuffix
+		}
+	}
+	for filePath, file := range pkg.Files {
+		if file == astFile || filePath == generatedFile {
+			continue
+		}
+		for _, decl := range file.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if !ok || funcDecl.Recv == nil || funcDecl.Name.Name != "String" {
+				continue
+			}
+			if enum, ok := enums[strings.TrimPrefix(astvisit.ExprString(funcDecl.Recv.List[0].Type), "*")]; ok {
+				enum.hasOtherString = true
+			}
+		}
+	}
+}
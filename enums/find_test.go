@@ -4,6 +4,7 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -614,3 +615,653 @@ func (s Status) Foo() string {
 		assert.NotEqual(t, "Foo", km.Name.Name)
 	}
 }
+
+func TestFind_Descriptions(t *testing.T) {
+	source := `package example
+
+type Status string //#enum
+
+const (
+	// StatusPending is waiting
+	// for confirmation
+	StatusPending Status = "pending"
+	StatusActive  Status = "active"
+	// StatusNone is not set
+	StatusNone Status = "" //#null
+)
+
+// StatusDone is finished
+const StatusDone Status = "done"`
+
+	fset, pkg, astFile := parseSource(t, source)
+	enums, err := Find(fset, pkg, astFile)
+	require.NoError(t, err)
+
+	e := enums["Status"]
+	assert.Equal(t, "test.go", e.File)
+	assert.Equal(t, []string{"StatusPending", "StatusActive", "StatusNone", "StatusDone"}, e.Enums)
+	assert.Equal(t, []string{"StatusPending is waiting\nfor confirmation", "", "StatusNone is not set", "StatusDone is finished"}, e.Descriptions)
+}
+
+func TestEnum_LiteralValues(t *testing.T) {
+	e := &Enum{Literals: []string{`"a"`, "`b`", "0x10", "-1", "1 << 3", "18446744073709551615"}}
+	values, err := e.LiteralValues()
+	require.NoError(t, err)
+	assert.Equal(t, []any{"a", "b", int64(16), int64(-1), int64(8), uint64(18446744073709551615)}, values)
+
+	e = &Enum{Literals: []string{"1.5"}}
+	_, err = e.LiteralValues()
+	assert.Error(t, err)
+}
+
+func TestFind_TypeCheckedLiteralValues(t *testing.T) {
+	source := `package example
+
+type Priority int //#enum
+
+const (
+	PriorityLow    Priority = iota + 1
+	PriorityMedium Priority = base
+	PriorityHigh   Priority = Priority(base + 2)
+	PriorityMax    Priority = 1 << 4
+)`
+	fset := token.NewFileSet()
+	astFile, err := parser.ParseFile(fset, "priority.go", source, parser.ParseComments)
+	require.NoError(t, err)
+	otherFile, err := parser.ParseFile(fset, "base.go", "package example\n\nconst base = 10\n", 0)
+	require.NoError(t, err)
+	pkg := &ast.Package{
+		Name:  "example",
+		Files: map[string]*ast.File{"priority.go": astFile, "base.go": otherFile},
+	}
+
+	enums, err := Find(fset, pkg, astFile)
+	require.NoError(t, err)
+	values, err := enums["Priority"].LiteralValues()
+	require.NoError(t, err)
+	assert.Equal(t, []any{int64(1), int64(10), int64(12), int64(16)}, values)
+
+	// Duplicates are detected by value instead of literal
+	source = strings.Replace(source, "1 << 4", "0x0a", 1)
+	fset, pkg, astFile = parseSource(t, strings.Replace(source, "base", "10", -1))
+	_, err = Find(fset, pkg, astFile)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "duplicate enum value 0x0a for type example.Priority")
+}
+
+func TestFind_TrailingCommentDescriptions(t *testing.T) {
+	source := `package example
+
+type Status string //#enum,description
+
+const (
+	// StatusPending is waiting
+	StatusPending Status = "pending" // ignored because of the doc comment
+	StatusActive  Status = "active"  // StatusActive is running
+	StatusNone    Status = ""        //#null
+)`
+
+	fset, pkg, astFile := parseSource(t, source)
+	enums, err := Find(fset, pkg, astFile)
+	require.NoError(t, err)
+
+	e := enums["Status"]
+	assert.True(t, e.Description)
+	assert.True(t, e.HasDescriptions())
+	assert.Equal(t, []string{"StatusPending is waiting", "StatusActive is running", ""}, e.Descriptions)
+	assert.Equal(t, []string{"StatusPending is waiting", "StatusActive is running"}, e.JSONSchemaDescriptions())
+}
+
+func TestFind_UnknownOption(t *testing.T) {
+	source := `package example
+
+type Status string //#enum,jsonshema
+
+const StatusActive Status = "active"`
+
+	fset, pkg, astFile := parseSource(t, source)
+	_, err := Find(fset, pkg, astFile)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown //#enum option "jsonshema"`)
+}
+
+func TestFind_StringMethodInOtherFile(t *testing.T) {
+	fset := token.NewFileSet()
+	parse := func(name, source string) *ast.File {
+		astFile, err := parser.ParseFile(fset, name, source, parser.ParseComments)
+		require.NoError(t, err)
+		return astFile
+	}
+	enumFile := parse("prio.go", `package example
+
+type Prio int //#enum,flag
+
+const PrioLow Prio = 1`)
+	pkg := &ast.Package{
+		Name: "example",
+		Files: map[string]*ast.File{
+			"prio.go": enumFile,
+			// Generated by stringer
+			"prio_string.go": parse("prio_string.go", `package example
+
+func (i Prio) String() string { return "" }`),
+		},
+	}
+
+	enums, err := Find(fset, pkg, enumFile)
+	require.NoError(t, err)
+	assert.False(t, enums["Prio"].generates("String", methodKind))
+	assert.True(t, enums["Prio"].generates("Set", methodKind))
+
+	// The generated file of the LayoutFile layout doesn't count
+	pkg.Files["prio_enum.go"] = pkg.Files["prio_string.go"]
+	delete(pkg.Files, "prio_string.go")
+	enums, err = Find(fset, pkg, enumFile)
+	require.NoError(t, err)
+	assert.True(t, enums["Prio"].generates("String", methodKind))
+}
+
+func TestFind_PGXFloatEnum(t *testing.T) {
+	source := `package example
+
+type Ratio float64 //#enum,pgx
+
+const RatioHalf Ratio = 0.5`
+
+	fset, pkg, astFile := parseSource(t, source)
+	_, err := Find(fset, pkg, astFile)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "pgx is only supported for string and integer enums, not float64")
+}
+
+func TestFind_Labels(t *testing.T) {
+	source := `package example
+
+type Priority int //#enum
+
+const (
+	PriorityNull Priority = 0 //#null //#label:"Not set"
+	// PriorityLow is the default
+	//#label:"Low, \"lowest\" // priority"
+	PriorityLow  Priority = 1
+	PriorityHigh Priority = 2
+)`
+
+	fset, pkg, astFile := parseSource(t, source)
+	enums, err := Find(fset, pkg, astFile)
+	require.NoError(t, err)
+
+	e := enums["Priority"]
+	assert.Equal(t, "PriorityNull", e.Null)
+	assert.True(t, e.HasLabels())
+	assert.Equal(t, []string{"Not set", `Low, "lowest" // priority`, ""}, e.Labels)
+	assert.Equal(t, []string{"Not set", `Low, "lowest" // priority`, "PriorityHigh"}, e.DisplayLabels())
+	assert.Equal(t, []string{"", "PriorityLow is the default", ""}, e.Descriptions)
+}
+
+func TestFind_InvalidLabel(t *testing.T) {
+	source := `package example
+
+type Status string //#enum
+
+const StatusActive Status = "active" //#label:Active`
+
+	fset, pkg, astFile := parseSource(t, source)
+	_, err := Find(fset, pkg, astFile)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "//#label of StatusActive needs one quoted string")
+}
+
+func TestFind_Aliases(t *testing.T) {
+	source := `package example
+
+type Status string //#enum
+
+const (
+	StatusCancelled Status = "cancelled" //#alias:"canceled","CANCELED"
+	StatusActive    Status = "active"
+)
+
+func ParseStatus(s string) (Status, error) {
+	return Status(s), nil
+}`
+
+	fset, pkg, astFile := parseSource(t, source)
+	enums, err := Find(fset, pkg, astFile)
+	require.NoError(t, err)
+
+	e := enums["Status"]
+	assert.Equal(t, [][]string{{"canceled", "CANCELED"}, nil}, e.Aliases)
+	assert.True(t, e.HasAliases())
+	assert.True(t, e.HasParser())
+	require.Len(t, e.KnownMethods, 1)
+	assert.Equal(t, "ParseStatus", e.KnownMethods[0].Name.Name)
+
+	cases, err := e.ParseCases()
+	require.NoError(t, err)
+	assert.Equal(t, []parseCase{
+		{Const: "StatusCancelled", Inputs: []string{`"cancelled"`, `"canceled"`, `"CANCELED"`}},
+		{Const: "StatusActive", Inputs: []string{`"active"`}},
+	}, cases)
+}
+
+func TestFind_AliasErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		errMsg string
+	}{
+		{
+			name: "collides with value",
+			source: `package example
+
+type Status string //#enum
+
+const (
+	StatusCancelled Status = "cancelled" //#alias:"active"
+	StatusActive    Status = "active"
+)`,
+			errMsg: `alias "active" of StatusCancelled for type example.Status in test.go:3 collides with StatusActive`,
+		},
+		{
+			name: "collides with alias",
+			source: `package example
+
+type Status string //#enum
+
+const (
+	StatusCancelled Status = "cancelled" //#alias:"x"
+	StatusActive    Status = "active"    //#alias:"x"
+)`,
+			errMsg: `alias "x" of StatusActive for type example.Status in test.go:3 collides with StatusCancelled`,
+		},
+		{
+			name: "int enum",
+			source: `package example
+
+type Priority int //#enum
+
+const PriorityLow Priority = 1 //#alias:"low"`,
+			errMsg: "//#alias of PriorityLow is only supported for string enums",
+		},
+		{
+			name: "unquoted",
+			source: `package example
+
+type Status string //#enum
+
+const StatusActive Status = "active" //#alias:ACTIVE`,
+			errMsg: "//#alias of StatusActive needs comma separated quoted strings",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fset, pkg, astFile := parseSource(t, tt.source)
+			_, err := Find(fset, pkg, astFile)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestFind_Normalize(t *testing.T) {
+	source := `package example
+
+type Status string //#enum,nocase,normalize=trim|snake
+
+const (
+	StatusOnHold Status = "on_hold" //#alias:"On Hold","ON-HOLD"
+	StatusActive Status = "Active"
+)`
+
+	fset, pkg, astFile := parseSource(t, source)
+	enums, err := Find(fset, pkg, astFile)
+	require.NoError(t, err)
+
+	e := enums["Status"]
+	require.NotNil(t, e)
+	assert.Equal(t, []string{NormalizeLower, NormalizeTrim, NormalizeSnake}, e.Normalize)
+	assert.True(t, e.HasParser())
+	assert.Equal(t, `strings.ReplaceAll(strings.ReplaceAll(strings.ToLower(strings.TrimSpace(s)), " ", "_"), "-", "_")`, e.ParseKey())
+
+	cases, err := e.ParseCases()
+	require.NoError(t, err)
+	// Aliases normalizing to the value are merged into one switch case input
+	assert.Equal(t, []parseCase{
+		{Const: "StatusOnHold", Inputs: []string{`"on_hold"`}},
+		{Const: "StatusActive", Inputs: []string{`"active"`}},
+	}, cases)
+}
+
+func TestFind_NormalizeErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		errMsg string
+	}{
+		{
+			name: "values collide",
+			source: `package example
+
+type Status string //#enum,nocase
+
+const (
+	StatusActive   Status = "active"
+	StatusActiveUC Status = "ACTIVE"
+)`,
+			errMsg: `value "ACTIVE" of StatusActiveUC for type example.Status in test.go:3 collides with StatusActive after normalization to "active"`,
+		},
+		{
+			name: "alias collides",
+			source: `package example
+
+type Status string //#enum,normalize=snake
+
+const (
+	StatusOnHold Status = "on_hold"
+	StatusPaused Status = "paused" //#alias:"On-Hold"
+)`,
+			errMsg: `alias "On-Hold" of StatusPaused for type example.Status in test.go:3 collides with StatusOnHold after normalization to "on_hold"`,
+		},
+		{
+			name: "unknown mode",
+			source: `package example
+
+type Status string //#enum,normalize=trim|upper
+
+const StatusActive Status = "active"`,
+			errMsg: `unknown normalize mode "upper", expected trim, lower or snake`,
+		},
+		{
+			name: "int enum",
+			source: `package example
+
+type Priority int //#enum,nocase
+
+const PriorityLow Priority = 1`,
+			errMsg: "normalize=lower is only supported for string enums",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fset, pkg, astFile := parseSource(t, tt.source)
+			_, err := Find(fset, pkg, astFile)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestFind_Unknown(t *testing.T) {
+	source := `package example
+
+type Status string //#enum
+
+const (
+	StatusActive  Status = "active"
+	StatusUnknown Status = "unknown" //#unknown:keep
+)
+
+type Priority int //#enum
+
+const (
+	PriorityLow     Priority = 1
+	PriorityUnknown Priority = -1 //#unknown
+)`
+
+	fset, pkg, astFile := parseSource(t, source)
+	enums, err := Find(fset, pkg, astFile)
+	require.NoError(t, err)
+
+	status := enums["Status"]
+	require.NotNil(t, status)
+	assert.Equal(t, "StatusUnknown", status.Unknown)
+	assert.True(t, status.KeepUnknown)
+	assert.True(t, status.HasParser())
+
+	priority := enums["Priority"]
+	require.NotNil(t, priority)
+	assert.Equal(t, "PriorityUnknown", priority.Unknown)
+	assert.False(t, priority.KeepUnknown)
+	assert.False(t, priority.HasParser())
+}
+
+func TestFind_UnknownErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		errMsg string
+	}{
+		{
+			name: "second unknown",
+			source: `package example
+
+type Status string //#enum
+
+const (
+	StatusUnknown Status = "unknown" //#unknown
+	StatusOther   Status = "other"   //#unknown
+)`,
+			errMsg: "second //#unknown enum encountered StatusOther",
+		},
+		{
+			name: "also null",
+			source: `package example
+
+type Status string //#enum
+
+const (
+	StatusNone   Status = ""      //#unknown //#null
+	StatusActive Status = "active"
+)`,
+			errMsg: "//#unknown enum StatusNone can't also be the //#null enum",
+		},
+		{
+			name: "invalid argument",
+			source: `package example
+
+type Status string //#enum
+
+const StatusUnknown Status = "unknown" //#unknown:drop`,
+			errMsg: `//#unknown of StatusUnknown has invalid argument "drop", expected none or keep`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fset, pkg, astFile := parseSource(t, tt.source)
+			_, err := Find(fset, pkg, astFile)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestFind_Default(t *testing.T) {
+	source := `package example
+
+type Status string //#enum
+
+const (
+	StatusActive   Status = "active" //#default:empty
+	StatusInactive Status = "inactive"
+)`
+
+	fset, pkg, astFile := parseSource(t, source)
+	enums, err := Find(fset, pkg, astFile)
+	require.NoError(t, err)
+
+	e := enums["Status"]
+	require.NotNil(t, e)
+	assert.Equal(t, "StatusActive", e.Default)
+	assert.True(t, e.DefaultOnEmpty)
+	assert.Equal(t, "StatusActive", e.SchemaDefault())
+
+	cases, err := e.ParseCases()
+	require.NoError(t, err)
+	assert.Equal(t, []string{`"active"`, `""`}, cases[0].Inputs)
+}
+
+func TestFind_DefaultErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		errMsg string
+	}{
+		{
+			name: "second default",
+			source: `package example
+
+type Status string //#enum
+
+const (
+	StatusActive   Status = "active"   //#default
+	StatusInactive Status = "inactive" //#default
+)`,
+			errMsg: "second //#default enum encountered StatusInactive",
+		},
+		{
+			name: "also null",
+			source: `package example
+
+type Status string //#enum
+
+const (
+	StatusNone   Status = ""       //#null //#default
+	StatusActive Status = "active"
+)`,
+			errMsg: "//#default enum StatusNone can't also be the //#null enum",
+		},
+		{
+			name: "empty collides with value",
+			source: `package example
+
+type Status string //#enum
+
+const (
+	StatusNone   Status = ""       //#null
+	StatusActive Status = "active" //#default:empty
+)`,
+			errMsg: `empty input "" of StatusActive for type example.Status in test.go:3 collides with StatusNone`,
+		},
+		{
+			name: "empty for int enum",
+			source: `package example
+
+type Priority int //#enum
+
+const PriorityLow Priority = 1 //#default:empty`,
+			errMsg: "//#default:empty of PriorityLow is only supported for string enums",
+		},
+		{
+			name: "invalid argument",
+			source: `package example
+
+type Status string //#enum
+
+const StatusActive Status = "active" //#default:first`,
+			errMsg: `//#default of StatusActive has invalid argument "first", expected none or empty`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fset, pkg, astFile := parseSource(t, tt.source)
+			_, err := Find(fset, pkg, astFile)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestFind_Deprecated(t *testing.T) {
+	source := `package example
+
+type Status string //#enum,ondeprecated
+
+const (
+	StatusActive Status = "active"
+	// StatusOld was used before v2.
+	//
+	// Deprecated: use StatusActive
+	StatusOld  Status = "old"
+	StatusGone Status = "gone" //#deprecated
+	// StatusPending is not Deprecated: only a paragraph prefix counts
+	StatusPending Status = "pending"
+)
+
+// OnDeprecatedStatus is a hand-written hook
+//#custom
+var OnDeprecatedStatus = func(Status) {}`
+
+	fset, pkg, astFile := parseSource(t, source)
+	enums, err := Find(fset, pkg, astFile)
+	require.NoError(t, err)
+
+	e := enums["Status"]
+	require.NotNil(t, e)
+	assert.True(t, e.OnDeprecated)
+	assert.Equal(t, []bool{false, true, true, false}, e.Deprecated)
+	assert.Equal(t, []string{"StatusActive", "StatusPending"}, e.CurrentEnums())
+	assert.Equal(t, []string{"StatusOld", "StatusGone"}, e.DeprecatedEnums())
+	assert.True(t, e.CustomMethods["OnDeprecatedStatus"])
+	assert.Empty(t, e.KnownVars)
+}
+
+func TestFind_RegistryInit(t *testing.T) {
+	source := `package example
+
+import "github.com/ungerik/go-enum/enumreg"
+
+type Status string //#enum,registry
+
+const StatusActive Status = "active"
+
+func init() {
+	println("not generated")
+}
+
+func init() {
+	enumreg.Register(enumreg.Definition[Status]{})
+}`
+
+	fset, pkg, astFile := parseSource(t, source)
+	enums, err := Find(fset, pkg, astFile)
+	require.NoError(t, err)
+
+	e := enums["Status"]
+	require.NotNil(t, e)
+	assert.True(t, e.Registry)
+	// Only the generated init function is replaced
+	require.Len(t, e.KnownMethods, 1)
+	assert.Equal(t, "init", e.KnownMethods[0].Name.Name)
+	assert.Len(t, e.KnownMethods[0].Body.List, 1)
+	assert.Equal(t, 13, fset.Position(e.KnownMethods[0].Pos()).Line)
+}
+
+func TestFind_EnumAssertion(t *testing.T) {
+	source := `package example
+
+import "github.com/ungerik/go-enum/enum"
+
+type Status string //#enum,assert
+
+const StatusActive Status = "active"
+
+var _ fmt.Stringer = StatusActive
+
+var _ enum.Enum[Status] = Status("")`
+
+	fset, pkg, astFile := parseSource(t, source)
+	enums, err := Find(fset, pkg, astFile)
+	require.NoError(t, err)
+
+	e := enums["Status"]
+	require.NotNil(t, e)
+	assert.True(t, e.Assert)
+	// Only the generated assertion is replaced
+	require.Len(t, e.KnownVars, 1)
+	assert.Equal(t, 11, fset.Position(e.KnownVars[0].Pos()).Line)
+}
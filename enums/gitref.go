@@ -0,0 +1,177 @@
+package enums
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// LoadGitRef finds all enums of the package at path like Load,
+// but in the version of the files at the git reference ref,
+// like a branch, tag, or commit of the repository containing path.
+//
+// The package directory, and the go.mod and ConfigFileName files
+// of the module root of the reference are streamed from git archive
+// into a temporary directory that is removed before returning,
+// so the Dir of the returned enums doesn't exist anymore.
+func LoadGitRef(ref, path string) ([]*Enum, error) {
+	recursive := strings.HasSuffix(path, "...")
+	absPath, err := filepath.Abs(strings.TrimSuffix(path, "..."))
+	if err != nil {
+		return nil, err
+	}
+	dir := absPath
+	if info, err := os.Stat(absPath); err == nil && !info.IsDir() {
+		dir = filepath.Dir(absPath)
+	}
+	topLevel, err := git(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	topLevel = strings.TrimSpace(topLevel)
+	// Resolve symlinks like git does for the top-level directory
+	if resolved, err := filepath.EvalSymlinks(absPath); err == nil {
+		absPath = resolved
+	}
+	rel, err := filepath.Rel(topLevel, absPath)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return nil, fmt.Errorf("%s is not in the git repository %s", path, topLevel)
+	}
+	paths, err := gitRefPaths(ref, topLevel, rel, recursive)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpDir, err := os.MkdirTemp("", "go-enum-"+filepath.Base(topLevel)+"-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+	if err := gitArchive(topLevel, ref, paths, tmpDir); err != nil {
+		return nil, err
+	}
+	refPath := filepath.Join(tmpDir, rel)
+	if recursive {
+		refPath = filepath.Join(refPath, "...")
+	}
+	return Load(refPath)
+}
+
+// gitRefPaths returns the pathspecs for git archive of the package
+// at rel relative to topLevel: its directory, or the directory tree
+// if recursive, and the go.mod and ConfigFileName files of the
+// module root if they exist at ref.
+func gitRefPaths(ref, topLevel, rel string, recursive bool) ([]string, error) {
+	dir := rel
+	if info, err := os.Stat(filepath.Join(topLevel, rel)); err == nil && !info.IsDir() {
+		dir = filepath.Dir(rel)
+	}
+	pkgPath := filepath.ToSlash(dir)
+	if !recursive {
+		// Only the files of the directory, not its sub-directories
+		pkgPath = ":(glob)" + path.Join(pkgPath, "*")
+	}
+	paths := []string{pkgPath}
+	root := moduleRoot(filepath.Join(topLevel, dir))
+	rootRel, err := filepath.Rel(topLevel, root)
+	if root == "" || err != nil || strings.HasPrefix(rootRel, "..") {
+		return paths, nil
+	}
+	for _, name := range []string{"go.mod", ConfigFileName} {
+		file := path.Join(filepath.ToSlash(rootRel), name)
+		if _, err := git(topLevel, "cat-file", "-e", ref+":"+file); err == nil {
+			paths = append(paths, file)
+		}
+	}
+	return paths, nil
+}
+
+// gitArchive extracts the files matching the pathspecs paths
+// at the git reference ref of the repository at topLevel to dir,
+// streaming the output of git archive.
+func gitArchive(topLevel, ref string, paths []string, dir string) error {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", append([]string{"archive", "--format=tar", ref, "--"}, paths...)...)
+	cmd.Dir = topLevel
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("git archive: %w", err)
+	}
+	extractErr := extractTar(stdout, dir)
+	if extractErr != nil {
+		_ = cmd.Process.Kill()
+	} else {
+		// Read the padding after the end of the archive
+		_, _ = io.Copy(io.Discard, stdout)
+	}
+	if err := cmd.Wait(); err != nil && extractErr == nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("git archive: %s", msg)
+		}
+		return fmt.Errorf("git archive: %w", err)
+	}
+	if extractErr != nil {
+		return fmt.Errorf("can't extract git reference %s: %w", ref, extractErr)
+	}
+	return nil
+}
+
+// git runs a git command in dir and returns its standard output.
+func git(dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return stdout.String(), nil
+}
+
+// extractTar writes the directories and regular files of a tar archive to dir.
+func extractTar(r io.Reader, dir string) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := filepath.FromSlash(header.Name)
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("invalid file name %q in archive", header.Name)
+		}
+		target := filepath.Join(dir, name)
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			content, err := io.ReadAll(tr)
+			if err != nil {
+				return err
+			}
+			if err := writeFile(target, content); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package enums
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Formats of the document written by WriteJSONSchema.
+const (
+	// JSONSchemaFormat writes a JSON Schema document with the enums under $defs
+	JSONSchemaFormat = "json"
+	// OpenAPIFormat writes OpenAPI 3.1 components/schemas as YAML
+	OpenAPIFormat = "openapi"
+)
+
+// jsonSchemaDraft is the $schema of documents in JSONSchemaFormat.
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// jsonSchema is the subset of JSON Schema used to describe enums.
+// The field order is the order of the marshaled properties.
+type jsonSchema struct {
+	Title         string        `json:"title,omitempty" yaml:"title,omitempty"`
+	Description   string        `json:"description,omitempty" yaml:"description,omitempty"`
+	Type          string        `json:"type,omitempty" yaml:"type,omitempty"`
+	Const         any           `json:"const,omitempty" yaml:"const,omitempty"`
+	Default       any           `json:"default,omitempty" yaml:"default,omitempty"`
+	Enum          []any         `json:"enum,omitempty" yaml:"enum,omitempty"`
+	XEnumVarnames []string      `json:"x-enum-varnames,omitempty" yaml:"x-enum-varnames,omitempty"`
+	OneOf         []*jsonSchema `json:"oneOf,omitempty" yaml:"oneOf,omitempty"`
+	Deprecated    bool          `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+}
+
+// jsonSchemaDefinition returns the JSON Schema of the enum with the type name as title,
+// the doc comment of the type as description, and the Go constant names
+// of the values as x-enum-varnames.
+//
+// Like for the generated JSONSchema method, the values are additionally
+// described as oneOf const schemas with the descriptions and deprecated
+// annotations if Enum.JSONSchemaOneOf returns true.
+//
+// Like for the generated JSONSchema method, a nullable enum
+// is described as oneOf the non-null values or null.
+// The value of a //#default constant is set as default.
+func (e *Enum) jsonSchemaDefinition() (*jsonSchema, error) {
+	values, err := e.LiteralValues()
+	if err != nil {
+		return nil, err
+	}
+	valuesSchema := &jsonSchema{Type: e.JSONType()}
+	for i, name := range e.Enums {
+		if name == e.Null {
+			continue
+		}
+		valuesSchema.Enum = append(valuesSchema.Enum, values[i])
+		valuesSchema.XEnumVarnames = append(valuesSchema.XEnumVarnames, name)
+		if e.JSONSchemaOneOf() {
+			valuesSchema.OneOf = append(valuesSchema.OneOf, &jsonSchema{
+				Const:       values[i],
+				Description: e.Descriptions[i],
+				Deprecated:  e.Deprecated[i],
+			})
+		}
+	}
+	schema := valuesSchema
+	if e.IsNullable() {
+		schema = &jsonSchema{
+			OneOf: []*jsonSchema{valuesSchema, {Type: "null"}},
+		}
+	}
+	schema.Title = e.Type
+	schema.Description = e.Doc
+	if e.Default != "" {
+		schema.Default = values[slices.Index(e.Enums, e.Default)]
+	}
+	return schema, nil
+}
+
+// JSONSchemaDocument returns a document with the schemas of the passed enums
+// keyed by their type names in the given format,
+// either JSONSchemaFormat or OpenAPIFormat.
+func JSONSchemaDocument(enums []*Enum, format string) ([]byte, error) {
+	schemas := make(map[string]*jsonSchema, len(enums))
+	for _, enum := range enums {
+		if _, exists := schemas[enum.Type]; exists {
+			return nil, fmt.Errorf("enum type name %s is used by more than one package", enum.Type)
+		}
+		schema, err := enum.jsonSchemaDefinition()
+		if err != nil {
+			return nil, err
+		}
+		schemas[enum.Type] = schema
+	}
+
+	var b bytes.Buffer
+	switch format {
+	case JSONSchemaFormat:
+		doc := struct {
+			Schema string                 `json:"$schema"`
+			Defs   map[string]*jsonSchema `json:"$defs"`
+		}{
+			Schema: jsonSchemaDraft,
+			Defs:   schemas,
+		}
+		enc := json.NewEncoder(&b)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(doc); err != nil {
+			return nil, err
+		}
+	case OpenAPIFormat:
+		var doc struct {
+			Components struct {
+				Schemas map[string]*jsonSchema `yaml:"schemas"`
+			} `yaml:"components"`
+		}
+		doc.Components.Schemas = schemas
+		enc := yaml.NewEncoder(&b)
+		enc.SetIndent(2)
+		if err := enc.Encode(doc); err != nil {
+			return nil, err
+		}
+		if err := enc.Close(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown JSON Schema format %q, expected %q or %q", format, JSONSchemaFormat, OpenAPIFormat)
+	}
+	return b.Bytes(), nil
+}
+
+// WriteJSONSchema writes a JSON Schema or OpenAPI document
+// with the enums found at path, see JSONSchemaDocument.
+// No Go code is compiled for this, the schemas are derived from the source.
+//
+// Parameters:
+//   - path: Directory or file path to process, may end with "..." to recurse
+//   - outFile: File for the generated document
+//   - format: JSONSchemaFormat or OpenAPIFormat
+//   - verboseOut: Writer for verbose progress output (nil to disable)
+//   - resultOut: Writer for generated document output (nil to write to outFile)
+//   - validate: If true, only check that outFile is up to date, an outdated file is returned as Result.Findings
+func WriteJSONSchema(path, outFile, format string, verboseOut, resultOut io.Writer, validate bool) (*Result, error) {
+	if outFile == "" && (resultOut == nil || validate) {
+		return nil, errors.New("no output file for JSON Schema document")
+	}
+	enums, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := JSONSchemaDocument(enums, format)
+	if err != nil {
+		return nil, err
+	}
+	return writeArtifacts(map[string][]byte{outFile: doc}, verboseOut, resultOut, validate)
+}
@@ -0,0 +1,220 @@
+package enums
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const jsonSchemaTestSource = `package example
+
+// Status represents order status
+type Status string //#enum
+
+const (
+	StatusPending Status = "pending"
+	StatusActive  Status = "active"
+)
+
+type Priority int //#enum
+
+const (
+	PriorityNull Priority = 0 //#null
+	PriorityLow  Priority = 1
+	PriorityHigh Priority = 2
+)
+`
+
+func TestJSONSchemaDocument_JSON(t *testing.T) {
+	fset, pkg, astFile := parseSource(t, jsonSchemaTestSource)
+	enums, err := Find(fset, pkg, astFile)
+	require.NoError(t, err)
+
+	doc, err := JSONSchemaDocument([]*Enum{enums["Status"], enums["Priority"]}, JSONSchemaFormat)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$defs": {
+			"Status": {
+				"title": "Status",
+				"description": "Status represents order status",
+				"type": "string",
+				"enum": ["pending", "active"],
+				"x-enum-varnames": ["StatusPending", "StatusActive"]
+			},
+			"Priority": {
+				"title": "Priority",
+				"oneOf": [
+					{
+						"type": "number",
+						"enum": [1, 2],
+						"x-enum-varnames": ["PriorityLow", "PriorityHigh"]
+					},
+					{"type": "null"}
+				]
+			}
+		}
+	}`, string(doc))
+}
+
+func TestJSONSchemaDocument_OpenAPI(t *testing.T) {
+	fset, pkg, astFile := parseSource(t, jsonSchemaTestSource)
+	enums, err := Find(fset, pkg, astFile)
+	require.NoError(t, err)
+
+	doc, err := JSONSchemaDocument([]*Enum{enums["Status"]}, OpenAPIFormat)
+	require.NoError(t, err)
+
+	assert.Equal(t, `components:
+  schemas:
+    Status:
+      title: Status
+      description: Status represents order status
+      type: string
+      enum:
+        - pending
+        - active
+      x-enum-varnames:
+        - StatusPending
+        - StatusActive
+`, string(doc))
+}
+
+func TestJSONSchemaDocument_Errors(t *testing.T) {
+	fset, pkg, astFile := parseSource(t, jsonSchemaTestSource)
+	enums, err := Find(fset, pkg, astFile)
+	require.NoError(t, err)
+
+	_, err = JSONSchemaDocument([]*Enum{enums["Status"]}, "xml")
+	assert.ErrorContains(t, err, "unknown JSON Schema format")
+
+	_, err = JSONSchemaDocument([]*Enum{enums["Status"], enums["Status"]}, JSONSchemaFormat)
+	assert.ErrorContains(t, err, "used by more than one package")
+}
+
+func TestWriteJSONSchema(t *testing.T) {
+	tmpDir := t.TempDir()
+	outFile := filepath.Join(tmpDir, "openapi", "enums.yaml")
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "status.go"), []byte(jsonSchemaTestSource), 0644))
+
+	_, err := WriteJSONSchema(tmpDir, outFile, OpenAPIFormat, nil, nil, true)
+	require.Error(t, err)
+	_, err = WriteJSONSchema(tmpDir, outFile, OpenAPIFormat, nil, nil, false)
+	require.NoError(t, err)
+	assert.FileExists(t, outFile)
+	_, err = WriteJSONSchema(tmpDir, outFile, OpenAPIFormat, nil, nil, true)
+	require.NoError(t, err)
+
+	// Validating against the other format reports the file as outdated
+	_, err = WriteJSONSchema(tmpDir, outFile, JSONSchemaFormat, nil, nil, true)
+	require.Error(t, err)
+}
+
+func TestJSONSchemaDocument_Descriptions(t *testing.T) {
+	source := `package example
+
+type Status string //#enum
+
+const (
+	// StatusOnHold is waiting for a customer response
+	StatusOnHold Status = "on_hold"
+	StatusActive Status = "active"
+)`
+
+	fset, pkg, astFile := parseSource(t, source)
+	enums, err := Find(fset, pkg, astFile)
+	require.NoError(t, err)
+
+	doc, err := JSONSchemaDocument([]*Enum{enums["Status"]}, JSONSchemaFormat)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$defs": {
+			"Status": {
+				"title": "Status",
+				"type": "string",
+				"enum": ["on_hold", "active"],
+				"x-enum-varnames": ["StatusOnHold", "StatusActive"],
+				"oneOf": [
+					{"const": "on_hold", "description": "StatusOnHold is waiting for a customer response"},
+					{"const": "active"}
+				]
+			}
+		}
+	}`, string(doc))
+}
+
+func TestJSONSchemaDocument_Default(t *testing.T) {
+	source := `package example
+
+type Priority int //#enum
+
+const (
+	PriorityNull   Priority = 0 //#null
+	PriorityNormal Priority = 1 //#default
+)
+`
+	fset, pkg, astFile := parseSource(t, source)
+	enums, err := Find(fset, pkg, astFile)
+	require.NoError(t, err)
+
+	doc, err := JSONSchemaDocument([]*Enum{enums["Priority"]}, JSONSchemaFormat)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$defs": {
+			"Priority": {
+				"title": "Priority",
+				"default": 1,
+				"oneOf": [
+					{
+						"type": "number",
+						"enum": [1],
+						"x-enum-varnames": ["PriorityNormal"]
+					},
+					{"type": "null"}
+				]
+			}
+		}
+	}`, string(doc))
+}
+
+func TestJSONSchemaDocument_Deprecated(t *testing.T) {
+	source := `package example
+
+type Status string //#enum
+
+const (
+	StatusActive Status = "active"
+	StatusOld    Status = "old" //#deprecated
+)
+`
+	fset, pkg, astFile := parseSource(t, source)
+	enums, err := Find(fset, pkg, astFile)
+	require.NoError(t, err)
+
+	doc, err := JSONSchemaDocument([]*Enum{enums["Status"]}, JSONSchemaFormat)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$defs": {
+			"Status": {
+				"title": "Status",
+				"type": "string",
+				"enum": ["active", "old"],
+				"x-enum-varnames": ["StatusActive", "StatusOld"],
+				"oneOf": [
+					{"const": "active"},
+					{"const": "old", "deprecated": true}
+				]
+			}
+		}
+	}`, string(doc))
+}
@@ -0,0 +1,240 @@
+package enums
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Formats of the inventory written by WriteList.
+const (
+	// ListTableFormat writes an aligned text table with one enum per line
+	ListTableFormat = "table"
+	// ListJSONFormat writes a JSON array of ListEntry objects
+	ListJSONFormat = "json"
+	// ListCSVFormat writes CSV with a header row and one enum per row
+	ListCSVFormat = "csv"
+)
+
+// ListEntry describes an enum type in the inventory written by List.
+type ListEntry struct {
+	// Package is the package name
+	Package string `json:"package"`
+	// Type is the enum type name
+	Type string `json:"type"`
+	// File is the slash separated path of the source file
+	// relative to the listed directory
+	File string `json:"file"`
+	// Line is the line number of the type declaration
+	Line int `json:"line"`
+	// Underlying is the underlying type
+	Underlying string `json:"underlying"`
+	// Values are the enum constants
+	Values []ListValue `json:"values"`
+	// Null is the name of the null constant, empty if not nullable
+	Null string `json:"null,omitempty"`
+	// Flags are the //#enum options including the configured ones
+	Flags []string `json:"flags"`
+	// CustomMethods are the names of the methods marked with //#custom
+	CustomMethods []string `json:"customMethods"`
+}
+
+// ListValue describes an enum constant of a ListEntry.
+type ListValue struct {
+	// Name is the name of the constant
+	Name string `json:"name"`
+	// Value is the string or integer value of the constant
+	Value any `json:"value"`
+	// Deprecated is true for deprecated constants
+	Deprecated bool `json:"deprecated,omitempty"`
+}
+
+// Flags returns the options of the enum in the syntax of
+// the comma separated list after the //#enum marker,
+// including options set by the configuration.
+func (e *Enum) Flags() []string {
+	var flags []string
+	for _, flag := range []struct {
+		name string
+		set  bool
+	}{
+		{"jsonschema", e.JSONSchema},
+		{"description", e.Description},
+		{"i18n", e.I18N},
+		{"registry", e.Registry},
+		{"assert", e.Assert},
+		{"slog", e.Slog},
+		{"flag", e.Flag},
+		{"pflag", e.PFlag},
+		{"pgx", e.PGX},
+		{"ondeprecated", e.OnDeprecated},
+		{"strict", e.Strict},
+	} {
+		if flag.set {
+			flags = append(flags, flag.name)
+		}
+	}
+	if len(e.Normalize) > 0 {
+		flags = append(flags, "normalize="+strings.Join(e.Normalize, "|"))
+	}
+	if e.IntString != "" && e.IntString != IntStringNumber {
+		flags = append(flags, "string="+e.IntString)
+	}
+	if len(e.Templates) > 0 {
+		flags = append(flags, "template="+strings.Join(e.Templates, "|"))
+	}
+	return flags
+}
+
+// listEntry returns the ListEntry of the enum
+// with the file path relative to root.
+func (e *Enum) listEntry(root string) (ListEntry, error) {
+	values, err := e.LiteralValues()
+	if err != nil {
+		return ListEntry{}, err
+	}
+	file := filepath.Join(e.Dir, e.File)
+	if rel, err := filepath.Rel(root, file); err == nil && !strings.HasPrefix(rel, "..") {
+		file = rel
+	}
+	entry := ListEntry{
+		Package:       e.Package,
+		Type:          e.Type,
+		File:          filepath.ToSlash(file),
+		Line:          e.Line,
+		Underlying:    e.Underlying,
+		Values:        make([]ListValue, len(e.Enums)),
+		Null:          e.Null,
+		Flags:         e.Flags(),
+		CustomMethods: slices.Sorted(maps.Keys(e.CustomMethods)),
+	}
+	if entry.Flags == nil {
+		entry.Flags = []string{}
+	}
+	if entry.CustomMethods == nil {
+		entry.CustomMethods = []string{}
+	}
+	for i, name := range e.Enums {
+		entry.Values[i] = ListValue{Name: name, Value: values[i], Deprecated: e.Deprecated[i]}
+	}
+	return entry, nil
+}
+
+// List returns an inventory of the passed enums in the given format,
+// either ListTableFormat, ListJSONFormat, or ListCSVFormat.
+// File paths are relative to the root directory.
+func List(enums []*Enum, root, format string) ([]byte, error) {
+	entries := make([]ListEntry, len(enums))
+	for i, enum := range enums {
+		entry, err := enum.listEntry(root)
+		if err != nil {
+			return nil, err
+		}
+		entries[i] = entry
+	}
+
+	var b bytes.Buffer
+	switch format {
+	case ListTableFormat:
+		w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "PACKAGE\tTYPE\tFILE\tUNDERLYING\tVALUES\tNULL\tFLAGS\tCUSTOM METHODS")
+		for _, entry := range entries {
+			fmt.Fprintf(w, "%s\t%s\t%s:%d\t%s\t%s\t%s\t%s\t%s\n",
+				entry.Package,
+				entry.Type,
+				entry.File, entry.Line,
+				entry.Underlying,
+				strings.Join(entry.valueNames(), ", "),
+				orDash(entry.Null),
+				orDash(strings.Join(entry.Flags, ", ")),
+				orDash(strings.Join(entry.CustomMethods, ", ")),
+			)
+		}
+		if err := w.Flush(); err != nil {
+			return nil, err
+		}
+	case ListJSONFormat:
+		enc := json.NewEncoder(&b)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(entries); err != nil {
+			return nil, err
+		}
+	case ListCSVFormat:
+		w := csv.NewWriter(&b)
+		_ = w.Write([]string{"package", "type", "file", "line", "underlying", "values", "null", "flags", "customMethods"})
+		for _, entry := range entries {
+			_ = w.Write([]string{
+				entry.Package,
+				entry.Type,
+				entry.File,
+				strconv.Itoa(entry.Line),
+				entry.Underlying,
+				strings.Join(entry.valueNames(), " "),
+				entry.Null,
+				strings.Join(entry.Flags, " "),
+				strings.Join(entry.CustomMethods, " "),
+			})
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown list format %q, expected %q, %q or %q", format, ListTableFormat, ListJSONFormat, ListCSVFormat)
+	}
+	return b.Bytes(), nil
+}
+
+// valueNames returns the names of the Values.
+func (e *ListEntry) valueNames() []string {
+	names := make([]string, len(e.Values))
+	for i, value := range e.Values {
+		names[i] = value.Name
+	}
+	return names
+}
+
+// orDash returns s or "-" for an empty s to mark empty table cells.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// WriteList writes an inventory of the enums found at path
+// to out, see List. File paths are relative to the directory of path.
+//
+// Parameters:
+//   - path: Directory or file path to process, may end with "..." to recurse
+//   - format: ListTableFormat, ListJSONFormat, or ListCSVFormat
+//   - out: Writer for the inventory
+func WriteList(path, format string, out io.Writer) error {
+	enums, err := Load(path)
+	if err != nil {
+		return err
+	}
+	root, err := filepath.Abs(strings.TrimSuffix(path, "..."))
+	if err != nil {
+		return err
+	}
+	if info, err := os.Stat(root); err == nil && !info.IsDir() {
+		root = filepath.Dir(root)
+	}
+	list, err := List(enums, root, format)
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(list)
+	return err
+}
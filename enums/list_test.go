@@ -0,0 +1,96 @@
+package enums
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const listTestSource = `package models
+
+type Status string //#enum,jsonschema,nocase
+
+const (
+	StatusActive   Status = "active"
+	StatusInactive Status = "inactive" //#deprecated
+)
+
+//#custom
+func (s Status) String() string { return string(s) }
+
+type Priority int //#enum
+
+const (
+	PriorityNull Priority = 0 //#null
+	PriorityHigh Priority = 1
+)
+`
+
+func writeListTestPackage(t *testing.T) string {
+	t.Helper()
+	tmpDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "models"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "models", "models.go"), []byte(listTestSource), 0644))
+	return tmpDir
+}
+
+func TestWriteList_JSON(t *testing.T) {
+	tmpDir := writeListTestPackage(t)
+
+	var out bytes.Buffer
+	require.NoError(t, WriteList(tmpDir+"/...", ListJSONFormat, &out))
+
+	var entries []ListEntry
+	require.NoError(t, json.Unmarshal(out.Bytes(), &entries))
+	require.Len(t, entries, 2)
+	assert.Equal(t, ListEntry{
+		Package:    "models",
+		Type:       "Status",
+		File:       "models/models.go",
+		Line:       3,
+		Underlying: "string",
+		Values: []ListValue{
+			{Name: "StatusActive", Value: "active"},
+			{Name: "StatusInactive", Value: "inactive", Deprecated: true},
+		},
+		Flags:         []string{"jsonschema", "normalize=lower"},
+		CustomMethods: []string{"String"},
+	}, entries[0])
+	assert.Equal(t, "Priority", entries[1].Type)
+	assert.Equal(t, "PriorityNull", entries[1].Null)
+	assert.Equal(t, []ListValue{{Name: "PriorityNull", Value: 0.0}, {Name: "PriorityHigh", Value: 1.0}}, entries[1].Values)
+	assert.Empty(t, entries[1].Flags)
+	assert.Contains(t, out.String(), `"customMethods": []`)
+}
+
+func TestWriteList_Table(t *testing.T) {
+	tmpDir := writeListTestPackage(t)
+
+	var out bytes.Buffer
+	require.NoError(t, WriteList(filepath.Join(tmpDir, "models"), ListTableFormat, &out))
+	assert.Equal(t, ""+
+		"PACKAGE  TYPE      FILE          UNDERLYING  VALUES                        NULL          FLAGS                        CUSTOM METHODS\n"+
+		"models   Status    models.go:3   string      StatusActive, StatusInactive  -             jsonschema, normalize=lower  String\n"+
+		"models   Priority  models.go:13  int         PriorityNull, PriorityHigh    PriorityNull  -                            -\n",
+		out.String())
+}
+
+func TestWriteList_CSV(t *testing.T) {
+	tmpDir := writeListTestPackage(t)
+
+	var out bytes.Buffer
+	require.NoError(t, WriteList(tmpDir+"/...", ListCSVFormat, &out))
+	assert.Equal(t, ""+
+		"package,type,file,line,underlying,values,null,flags,customMethods\n"+
+		"models,Status,models/models.go,3,string,StatusActive StatusInactive,,jsonschema normalize=lower,String\n"+
+		"models,Priority,models/models.go,13,int,PriorityNull PriorityHigh,PriorityNull,,\n",
+		out.String())
+
+	err := WriteList(tmpDir+"/...", "xml", &out)
+	assert.EqualError(t, err, `unknown list format "xml", expected "table", "json" or "csv"`)
+}
@@ -0,0 +1,79 @@
+package enums
+
+import (
+	"cmp"
+	"go/ast"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+
+	"golang.org/x/mod/modfile"
+)
+
+// Load finds all enums of the package at path without modifying any files.
+//
+// Like for Rewrite, the path may end with "..." to load the enums of all
+// packages in the directory tree, test files and main packages are skipped,
+// and the ConfigFileName file in the module root is applied.
+// The result is sorted by package directory, file and line, so that
+// artifacts generated from it are deterministic.
+func Load(path string) ([]*Enum, error) {
+	config, err := FindConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		result      []*Enum
+		importPaths = make(map[string]string)
+	)
+	err = sourceFS{}.walkFiles(path, func(fset *token.FileSet, pkg *ast.Package, astFile *ast.File, filePath string) error {
+		dir := filepath.Dir(filePath)
+		enums, err := find(fset, pkg, astFile, config.ForDir(dir), nil)
+		if err != nil {
+			return err
+		}
+		importPath, ok := importPaths[dir]
+		if !ok {
+			importPath = packageImportPath(dir)
+			importPaths[dir] = importPath
+		}
+		for _, enum := range enums {
+			enum.ImportPath = importPath
+			result = append(result, enum)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(result, func(a, b *Enum) int {
+		return cmp.Or(
+			cmp.Compare(a.Dir, b.Dir),
+			cmp.Compare(a.File, b.File),
+			cmp.Compare(a.Line, b.Line),
+		)
+	})
+	return result, nil
+}
+
+// packageImportPath returns the import path of the package in dir
+// derived from the module path of its go.mod file,
+// or an empty string if dir is not in a module.
+func packageImportPath(dir string) string {
+	root := moduleRoot(dir)
+	if root == "" {
+		return ""
+	}
+	data, err := os.ReadFile(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	modulePath := modfile.ModulePath(data)
+	rel, err := filepath.Rel(root, dir)
+	if modulePath == "" || err != nil {
+		return ""
+	}
+	return path.Join(modulePath, filepath.ToSlash(rel))
+}
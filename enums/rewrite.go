@@ -2,14 +2,20 @@ package enums
 
 import (
 	"bytes"
+	"cmp"
+	"context"
+	"errors"
 	"fmt"
-	"go/ast"
+	"go/format"
+	"go/parser"
 	"go/token"
 	"io"
+	"maps"
 	"os"
-	"text/template"
+	"slices"
 
 	"github.com/ungerik/go-astvisit"
+	"golang.org/x/tools/go/ast/astutil"
 )
 
 // Rewrite scans Go source files at the given path for enum type definitions
@@ -20,15 +26,27 @@ import (
 // methods based on flags (String, IsNull, MarshalJSON, Scan, Value, JSONSchema).
 //
 // The generated methods either replace existing enum methods or are inserted
-// after the last enum constant declaration.
+// after the last enum constant declaration. With the LayoutFile layout of the
+// Config they are written to a separate file per source file instead.
 //
-// Parameters:
-//   - path: Directory or file path to process (defaults to current directory)
-//   - verboseOut: Writer for verbose progress output (nil to disable)
-//   - resultOut: Writer for generated code output (nil to write to files)
-//   - debug: If true, inserts debug comments in generated code
-func Rewrite(path string, verboseOut io.Writer, resultOut io.Writer, debug bool) error {
-	return rewrite(path, verboseOut, resultOut, debug, false)
+// The path is a directory or file path, a directory path ending
+// with "..." also processes all sub-directories.
+// Rewrite is Run with the path and the findings
+// of options.Validate reported to stderr.
+// With options.Validate the LockFileName file of the module
+// is validated as well if it exists, see ValidateLockFile.
+func Rewrite(path string, options Options) error {
+	options.Path = path
+	result, err := Run(context.Background(), options)
+	if result != nil {
+		for _, finding := range result.Findings {
+			fmt.Fprintln(os.Stderr, finding)
+		}
+	}
+	if options.Validate {
+		err = errors.Join(err, ValidateLockFile(path))
+	}
+	return err
 }
 
 // ValidateRewrite checks if enum methods are missing or outdated without modifying files.
@@ -44,193 +62,123 @@ func Rewrite(path string, verboseOut io.Writer, resultOut io.Writer, debug bool)
 //
 // Returns an error if any enum methods are missing or outdated.
 func ValidateRewrite(path string, verboseOut io.Writer, debug bool) error {
-	return rewrite(path, verboseOut, nil, debug, true)
+	return Rewrite(path, Options{VerboseOut: verboseOut, Debug: debug, Validate: true})
 }
 
-func rewrite(path string, verboseOut io.Writer, resultOut io.Writer, debug bool, validate bool) error {
-	var validationErrors []string
-
-	err := astvisit.RewriteWithReplacements(
-		path,
-		verboseOut,
-		resultOut,
-		debug,
-		func(fset *token.FileSet, pkg *ast.Package, astFile *ast.File, filePath string, verboseOut io.Writer) (astvisit.NodeReplacements, astvisit.Imports, error) {
-			// ast.Print(fset, astFile)
-			// return nil, nil
-
-			enums, err := Find(fset, pkg, astFile)
-			if err != nil {
-				return nil, nil, err
-			}
-			if len(enums) == 0 {
-				return nil, nil, nil
-			}
-
-			var (
-				replacements astvisit.NodeReplacements
-				imports      = make(astvisit.Imports)
-			)
-			for _, enum := range enums {
-				var methods bytes.Buffer
-				imports[`"fmt"`] = struct{}{}
-				alwaysTmpls := []struct {
-					name string
-					tmpl *template.Template
-				}{
-					{"Valid", validTemplate},
-					{"Validate", validateTemplate},
-					{"Enums", enumsTemplate},
-					{"EnumStrings", enumStringsTemplate},
-				}
-				for _, t := range alwaysTmpls {
-					if enum.CustomMethods[t.name] {
-						continue
-					}
-					if err := t.tmpl.Execute(&methods, enum); err != nil {
-						return nil, nil, err
-					}
-				}
-				if enum.IsStringType() && !enum.CustomMethods["String"] {
-					err := stringMethodsTemplate.Execute(&methods, enum)
-					if err != nil {
-						return nil, nil, err
-					}
-				}
-				if enum.IsNullable() {
-					imports[`"bytes"`] = struct{}{}
-					imports[`"encoding/json"`] = struct{}{}
-					nullableTmpls := []struct {
-						name string
-						tmpl *template.Template
-					}{
-						{"IsNull", isNullTemplate},
-						{"IsNotNull", isNotNullTemplate},
-						{"SetNull", setNullTemplate},
-						{"MarshalJSON", nullableMarshalJSONTemplate},
-						{"UnmarshalJSON", nullableUnmarshalJSONTemplate},
-					}
-					for _, t := range nullableTmpls {
-						if enum.CustomMethods[t.name] {
-							continue
-						}
-						if err := t.tmpl.Execute(&methods, enum); err != nil {
-							return nil, nil, err
-						}
-					}
-					var scanTmpl, valueTmpl *template.Template
-					switch {
-					case enum.IsStringType():
-						imports[`"database/sql/driver"`] = struct{}{}
-						scanTmpl = nullableStringScanTemplate
-						valueTmpl = nullableStringValueTemplate
-					case enum.IsIntType():
-						imports[`"database/sql/driver"`] = struct{}{}
-						scanTmpl = nullableIntScanTemplate
-						valueTmpl = nullableIntValueTemplate
-					}
-					if scanTmpl != nil && !enum.CustomMethods["Scan"] {
-						if err := scanTmpl.Execute(&methods, enum); err != nil {
-							return nil, nil, err
-						}
-					}
-					if valueTmpl != nil && !enum.CustomMethods["Value"] {
-						if err := valueTmpl.Execute(&methods, enum); err != nil {
-							return nil, nil, err
-						}
-					}
-				}
-				if enum.JSONSchema && !enum.CustomMethods["JSONSchema"] {
-					imports[`"github.com/invopop/jsonschema"`] = struct{}{}
-					if err := jsonSchemaMethodTemplate.Execute(&methods, enum); err != nil {
-						return nil, nil, err
-					}
-				}
-
-				debugID := "Replacement for " + enum.Type
-				if len(enum.KnownMethods) == 0 {
-					// No existing methods to replace,
-					// insert new methods after last enum declaration
-					replacements.AddInsertAfter(enum.LastEnumDecl, methods.Bytes(), debugID)
-					continue
-				}
-				for i, method := range enum.KnownMethods {
-					methodWithDoc := astvisit.NodeRange{method}
-					if method.Doc != nil {
-						methodWithDoc = append(methodWithDoc, method.Doc)
-					}
-					if i == 0 {
-						// Replace the first existing method with all new ones
-						replacements.AddReplacement(methodWithDoc, methods.Bytes(), debugID)
-					} else {
-						// Remove all further existing methods
-						replacements.AddRemoval(methodWithDoc, debugID)
-					}
-				}
-			}
+// generate executes the templates of the enum
+// and adds the imports of the generated code.
+func (e *Enum) generate(imports astvisit.Imports) ([]byte, error) {
+	if err := e.checkUserTemplates(); err != nil {
+		return nil, err
+	}
+	var methods bytes.Buffer
+	imports[`"fmt"`] = struct{}{}
+	for _, t := range e.methodTemplates() {
+		if e.CustomMethods[t.name] {
+			continue
+		}
+		for _, imp := range t.imports {
+			imports[imp] = struct{}{}
+		}
+		if err := t.tmpl.Execute(&methods, e); err != nil {
+			return nil, err
+		}
+	}
+	return methods.Bytes(), nil
+}
 
-			// Drop replacements when they produce no semantic change. Both
-			// the source and the rewritten output are normalized via
-			// FormatFileWithImports before comparison so that purely
-			// cosmetic differences (import reordering, whitespace) cancel
-			// out instead of being reported as missing/outdated methods.
-			// Dropping the replacements when nothing changes also avoids
-			// rewriting the file in normal mode, so a file with already
-			// up-to-date methods stays byte-identical even when its imports
-			// were not in the order goimports would produce.
-			if len(replacements) > 0 {
-				source, err := os.ReadFile(filePath)
-				if err != nil {
-					return nil, nil, err
-				}
-				rewritten, err := replacements.Apply(fset, source)
-				if err != nil {
-					return nil, nil, err
-				}
-				formattedSource, err := astvisit.FormatFileWithImports(fset, source, imports)
-				if err != nil {
-					return nil, nil, err
-				}
-				formattedRewritten, err := astvisit.FormatFileWithImports(fset, rewritten, imports)
-				if err != nil {
-					return nil, nil, err
-				}
-				if bytes.Equal(formattedSource, formattedRewritten) {
-					replacements = nil
-				}
-			}
+// sortedByLine returns the enums sorted by their line in the source file.
+func sortedByLine(enums map[string]*Enum) []*Enum {
+	sorted := slices.Collect(maps.Values(enums))
+	slices.SortFunc(sorted, func(a, b *Enum) int {
+		return cmp.Compare(a.Line, b.Line)
+	})
+	return sorted
+}
 
-			if validate {
-				for _, repl := range replacements {
-					var pos token.Position
-					if repl.Node != nil {
-						pos = fset.Position(repl.Node.Pos())
-					}
-					desc := repl.DebugID
-					if desc == "" {
-						desc = "enum methods"
-					}
-					validationErrors = append(validationErrors, fmt.Sprintf("%s: missing or outdated %s", pos, desc))
-				}
+// generatedFileHeader starts the files of the LayoutFile layout.
+// An existing file without it is not overwritten.
+const generatedFileHeader = "// Code generated by go-enum"
 
-				// Return nil to prevent file modification in validate mode
-				return nil, nil, nil
-			}
+// generatedFileContent returns the formatted content of the file at filePath
+// of the LayoutFile layout with the methods generated for sourceFile.
+// The existing content of the file must be nil or start with generatedFileHeader.
+func generatedFileContent(existing []byte, filePath, pkgName, sourceFile string, methods []byte, imports astvisit.Imports) ([]byte, error) {
+	if existing != nil && !bytes.HasPrefix(existing, []byte(generatedFileHeader)) {
+		return nil, fmt.Errorf("can't write generated methods to %s because the file was not generated by go-enum", filePath)
+	}
+	var source bytes.Buffer
+	fmt.Fprintf(&source, "%s from %s. DO NOT EDIT.\n\npackage %s\n", generatedFileHeader, sourceFile, pkgName)
+	source.Write(methods)
+	return astvisit.FormatFileWithImports(token.NewFileSet(), source.Bytes(), imports)
+}
 
-			return replacements, imports, nil
-		},
-	)
+// removeUnusedImports removes the imports of generated code
+// that are not used anymore after moving the generated code
+// from source to the file of the LayoutFile layout.
+func removeUnusedImports(source []byte, generatedImports astvisit.Imports) ([]byte, error) {
+	fset := token.NewFileSet()
+	astFile, err := parser.ParseFile(fset, "", source, parser.ParseComments)
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	// In validation mode, report errors and fail if any were found
-	if validate && len(validationErrors) > 0 {
-		for _, errMsg := range validationErrors {
-			fmt.Fprintln(os.Stderr, errMsg)
+	removed := false
+	for _, importLine := range generatedImports.Sorted() {
+		name, path, err := astvisit.ImportNameAndPathOfImportLine(importLine)
+		if err != nil {
+			return nil, err
+		}
+		if !astutil.UsesImport(astFile, path) && astutil.DeleteNamedImport(fset, astFile, name, path) {
+			removed = true
 		}
-		return fmt.Errorf("found %d missing or outdated enum method(s)", len(validationErrors))
 	}
+	if !removed {
+		return source, nil
+	}
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, astFile); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
 
-	return nil
+// existingDeclNames returns the sorted names of the
+// known and custom declarations existing in the source.
+func (e *Enum) existingDeclNames() []string {
+	var names []string
+	for _, method := range e.KnownMethods {
+		names = append(names, method.Name.Name)
+	}
+	for _, genDecl := range e.KnownVars {
+		names = append(names, varDeclName(genDecl))
+	}
+	for name := range e.CustomMethods {
+		names = append(names, "//#custom "+name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// knownDecls returns the existing generated methods, functions,
+// and variables of the enum with their doc comments in source order.
+func (e *Enum) knownDecls() []astvisit.NodeRange {
+	var known []astvisit.NodeRange
+	for _, method := range e.KnownMethods {
+		methodWithDoc := astvisit.NodeRange{method}
+		if method.Doc != nil {
+			methodWithDoc = append(methodWithDoc, method.Doc)
+		}
+		known = append(known, methodWithDoc)
+	}
+	for _, genDecl := range e.KnownVars {
+		varWithDoc := astvisit.NodeRange{genDecl}
+		if genDecl.Doc != nil {
+			varWithDoc = append(varWithDoc, genDecl.Doc)
+		}
+		known = append(known, varWithDoc)
+	}
+	slices.SortFunc(known, func(a, b astvisit.NodeRange) int {
+		return cmp.Compare(a[0].Pos(), b[0].Pos())
+	})
+	return known
 }
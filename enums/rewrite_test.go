@@ -2,10 +2,17 @@ package enums
 
 import (
 	"bytes"
+	"context"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -33,7 +40,7 @@ const (
 
 	// Run Rewrite
 	var output bytes.Buffer
-	err = Rewrite(tmpDir, nil, &output, false)
+	err = Rewrite(tmpDir, Options{ResultOut: &output})
 	require.NoError(t, err)
 
 	result := output.String()
@@ -74,7 +81,7 @@ const (
 	require.NoError(t, err)
 
 	var output bytes.Buffer
-	err = Rewrite(tmpDir, nil, &output, false)
+	err = Rewrite(tmpDir, Options{ResultOut: &output})
 	require.NoError(t, err)
 
 	result := output.String()
@@ -112,7 +119,7 @@ const (
 	require.NoError(t, err)
 
 	var output bytes.Buffer
-	err = Rewrite(tmpDir, nil, &output, false)
+	err = Rewrite(tmpDir, Options{ResultOut: &output})
 	require.NoError(t, err)
 
 	result := output.String()
@@ -150,7 +157,7 @@ const (
 	require.NoError(t, err)
 
 	var output bytes.Buffer
-	err = Rewrite(tmpDir, nil, &output, false)
+	err = Rewrite(tmpDir, Options{ResultOut: &output})
 	require.NoError(t, err)
 
 	result := output.String()
@@ -185,7 +192,7 @@ const (
 	require.NoError(t, err)
 
 	var output bytes.Buffer
-	err = Rewrite(tmpDir, nil, &output, false)
+	err = Rewrite(tmpDir, Options{ResultOut: &output})
 	require.NoError(t, err)
 
 	result := output.String()
@@ -218,7 +225,7 @@ const (
 	require.NoError(t, err)
 
 	var output bytes.Buffer
-	err = Rewrite(tmpDir, nil, &output, false)
+	err = Rewrite(tmpDir, Options{ResultOut: &output})
 	require.NoError(t, err)
 
 	result := output.String()
@@ -266,7 +273,7 @@ func (s Status) String() string {
 	require.NoError(t, err)
 
 	var output bytes.Buffer
-	err = Rewrite(tmpDir, nil, &output, false)
+	err = Rewrite(tmpDir, Options{ResultOut: &output})
 	require.NoError(t, err)
 
 	result := output.String()
@@ -307,7 +314,7 @@ const (
 	require.NoError(t, err)
 
 	var output bytes.Buffer
-	err = Rewrite(tmpDir, nil, &output, false)
+	err = Rewrite(tmpDir, Options{ResultOut: &output})
 	require.NoError(t, err)
 
 	result := output.String()
@@ -336,7 +343,7 @@ const (
 	require.NoError(t, err)
 
 	var output bytes.Buffer
-	err = Rewrite(tmpDir, nil, &output, false)
+	err = Rewrite(tmpDir, Options{ResultOut: &output})
 	require.NoError(t, err)
 
 	result := output.String()
@@ -362,7 +369,7 @@ const (
 	require.NoError(t, err)
 
 	// Run Rewrite without resultOut (writes to file)
-	err = Rewrite(tmpDir, nil, nil, false)
+	err = Rewrite(tmpDir, Options{})
 	require.NoError(t, err)
 
 	// Read the file back
@@ -395,7 +402,7 @@ const (
 
 	var verbose bytes.Buffer
 	var output bytes.Buffer
-	err = Rewrite(tmpDir, &verbose, &output, false)
+	err = Rewrite(tmpDir, Options{VerboseOut: &verbose, ResultOut: &output})
 	require.NoError(t, err)
 
 	verboseStr := verbose.String()
@@ -422,7 +429,7 @@ const (
 	require.NoError(t, err)
 
 	var output bytes.Buffer
-	err = Rewrite(tmpDir, nil, &output, true)
+	err = Rewrite(tmpDir, Options{ResultOut: &output, Debug: true})
 	require.NoError(t, err)
 
 	result := output.String()
@@ -450,7 +457,7 @@ const (
 	require.NoError(t, err)
 
 	var output bytes.Buffer
-	err = Rewrite(tmpDir, nil, &output, false)
+	err = Rewrite(tmpDir, Options{ResultOut: &output})
 	require.NoError(t, err)
 
 	result := output.String()
@@ -479,7 +486,7 @@ const (
 	require.NoError(t, err)
 
 	var output bytes.Buffer
-	err = Rewrite(tmpDir, nil, &output, false)
+	err = Rewrite(tmpDir, Options{ResultOut: &output})
 	require.NoError(t, err)
 
 	result := output.String()
@@ -507,7 +514,7 @@ const (
 	require.NoError(t, err)
 
 	var output bytes.Buffer
-	err = Rewrite(tmpDir, nil, &output, false)
+	err = Rewrite(tmpDir, Options{ResultOut: &output})
 	require.NoError(t, err)
 
 	result := output.String()
@@ -535,7 +542,7 @@ const (
 	require.NoError(t, err)
 
 	var output bytes.Buffer
-	err = Rewrite(tmpDir, nil, &output, false)
+	err = Rewrite(tmpDir, Options{ResultOut: &output})
 	require.NoError(t, err)
 
 	result := output.String()
@@ -562,7 +569,7 @@ const (
 	require.NoError(t, err)
 
 	var output bytes.Buffer
-	err = Rewrite(tmpDir, nil, &output, false)
+	err = Rewrite(tmpDir, Options{ResultOut: &output})
 	require.NoError(t, err)
 
 	result := output.String()
@@ -590,7 +597,7 @@ const (
 	require.NoError(t, err)
 
 	var output bytes.Buffer
-	err = Rewrite(tmpDir, nil, &output, false)
+	err = Rewrite(tmpDir, Options{ResultOut: &output})
 	require.NoError(t, err)
 
 	result := output.String()
@@ -642,7 +649,7 @@ const (
 	require.NoError(t, err)
 
 	// Generate methods
-	err = Rewrite(tmpDir, nil, nil, false)
+	err = Rewrite(tmpDir, Options{})
 	require.NoError(t, err)
 
 	// Now validate - should succeed
@@ -832,7 +839,7 @@ const (
 	require.NoError(t, err)
 
 	// Generate methods
-	err = Rewrite(tmpDir, nil, nil, false)
+	err = Rewrite(tmpDir, Options{})
 	require.NoError(t, err)
 
 	// Validate - should pass
@@ -908,7 +915,7 @@ const (
 	require.NoError(t, err)
 
 	// Generate methods
-	err = Rewrite(tmpDir, nil, nil, false)
+	err = Rewrite(tmpDir, Options{})
 	require.NoError(t, err)
 
 	// Validate - should pass
@@ -984,7 +991,7 @@ const (
 	require.NoError(t, err)
 
 	// Generate methods
-	err = Rewrite(tmpDir, nil, nil, false)
+	err = Rewrite(tmpDir, Options{})
 	require.NoError(t, err)
 
 	// Validate - should pass
@@ -1063,7 +1070,7 @@ const (
 `
 	err := os.WriteFile(testFile, []byte(source), 0644)
 	require.NoError(t, err)
-	require.NoError(t, Rewrite(tmpDir, nil, nil, false))
+	require.NoError(t, Rewrite(tmpDir, Options{}))
 
 	// Read the freshly-generated file, then deliberately scramble its
 	// import order so it is no longer goimports-sorted.
@@ -1086,7 +1093,7 @@ const (
 
 	// Run Rewrite again. Methods are already up to date, so the file must
 	// be left byte-identical — no import reordering allowed.
-	require.NoError(t, Rewrite(tmpDir, nil, nil, false))
+	require.NoError(t, Rewrite(tmpDir, Options{}))
 	after, err := os.ReadFile(testFile)
 	require.NoError(t, err)
 	assert.Equal(t, string(scrambled), string(after), "no-op Rewrite must not reorder imports")
@@ -1138,7 +1145,7 @@ func (s *Status) UnmarshalJSON(data []byte) error {
 
 	require.NoError(t, os.WriteFile(testFile, []byte(source), 0644))
 
-	require.NoError(t, Rewrite(tmpDir, nil, nil, false))
+	require.NoError(t, Rewrite(tmpDir, Options{}))
 	written, err := os.ReadFile(testFile)
 	require.NoError(t, err)
 	result := string(written)
@@ -1170,7 +1177,7 @@ func (s *Status) UnmarshalJSON(data []byte) error {
 	assert.Contains(t, result, "func (s Status) Valid() bool")
 
 	// Running again must be a no-op (idempotent).
-	require.NoError(t, Rewrite(tmpDir, nil, nil, false))
+	require.NoError(t, Rewrite(tmpDir, Options{}))
 	secondPass, err := os.ReadFile(testFile)
 	require.NoError(t, err)
 	assert.Equal(t, string(written), string(secondPass),
@@ -1246,7 +1253,7 @@ const (
 `
 			require.NoError(t, os.WriteFile(testFile, []byte(source), 0644))
 
-			require.NoError(t, Rewrite(tmpDir, nil, nil, false))
+			require.NoError(t, Rewrite(tmpDir, Options{}))
 			written, err := os.ReadFile(testFile)
 			require.NoError(t, err)
 			result := string(written)
@@ -1271,7 +1278,7 @@ const (
 			}
 
 			// Second Rewrite must be idempotent.
-			require.NoError(t, Rewrite(tmpDir, nil, nil, false))
+			require.NoError(t, Rewrite(tmpDir, Options{}))
 			secondPass, err := os.ReadFile(testFile)
 			require.NoError(t, err)
 			assert.Equal(t, string(written), string(secondPass),
@@ -1282,3 +1289,788 @@ const (
 		})
 	}
 }
+
+func TestRewrite_Description(t *testing.T) {
+	source := `package example
+
+type Status string //#enum,description,jsonschema
+
+const (
+	// StatusOnHold is waiting for
+	// a "customer" response
+	StatusOnHold Status = "on_hold"
+	StatusActive Status = "active" // StatusActive is processed
+	StatusDone   Status = "done"
+)
+`
+	result := rewriteSource(t, "status.go", source)
+
+	assert.Contains(t, result, "func (s Status) Description() string {")
+	assert.Contains(t, result, "case StatusOnHold:\n\t\treturn \"StatusOnHold is waiting for\\na \\\"customer\\\" response\"")
+	assert.Contains(t, result, "case StatusActive:\n\t\treturn \"StatusActive is processed\"")
+	assert.NotContains(t, result, "case StatusDone:")
+
+	assert.Contains(t, result, `{Const: "active", Description: "StatusActive is processed"},`)
+	assert.Contains(t, result, `{Const: "done"},`)
+}
+
+func TestRewrite_NoDescriptionWithoutOption(t *testing.T) {
+	source := `package example
+
+type Status string //#enum,jsonschema
+
+const (
+	// StatusActive is processed
+	StatusActive Status = "active"
+)
+`
+	result := rewriteSource(t, "status.go", source)
+
+	assert.NotContains(t, result, "Description()")
+	// The JSON Schema describes the values like the standalone export
+	assert.Contains(t, result, `{Const: "active", Description: "StatusActive is processed"},`)
+}
+
+func TestRewrite_Labels(t *testing.T) {
+	source := `package example
+
+type Status string //#enum,i18n
+
+const (
+	StatusOnHold Status = "on_hold" //#label:"On hold (100%)"
+	StatusActive Status = "active"
+)
+
+type Priority int //#enum
+
+const (
+	PriorityLow  Priority = 1 //#label:"Low"
+	PriorityHigh Priority = 2
+)
+`
+	result := rewriteSource(t, "status.go", source)
+
+	assert.Contains(t, result, "func (s Status) Label() string {")
+	assert.Contains(t, result, "case StatusOnHold:\n\t\treturn \"On hold (100%)\"")
+	assert.Contains(t, result, "case StatusActive:\n\t\treturn \"active\"")
+	assert.Contains(t, result, "return string(s)\n}")
+
+	assert.Contains(t, result, "func (s Status) LabelFor(lang language.Tag) string {")
+	assert.Contains(t, result, `message.NewPrinter(lang).Sprintf(message.Key("Status.StatusOnHold", "On hold (100%%)"))`)
+	assert.Contains(t, result, `"golang.org/x/text/language"`)
+	assert.Contains(t, result, `"golang.org/x/text/message"`)
+
+	assert.Contains(t, result, "func (p Priority) Label() string {")
+	assert.Contains(t, result, "case PriorityHigh:\n\t\treturn \"PriorityHigh\"")
+	assert.Contains(t, result, "return fmt.Sprint(int(p))\n}")
+	assert.NotContains(t, result, "func (p Priority) LabelFor(")
+}
+
+func TestRewrite_Aliases(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "status.go")
+
+	source := `package example
+
+type Status string //#enum
+
+const (
+	StatusCancelled Status = "cancelled" //#alias:"canceled","CANCELED"
+	StatusActive    Status = "active"
+)
+`
+	require.NoError(t, os.WriteFile(testFile, []byte(source), 0644))
+
+	require.NoError(t, Rewrite(tmpDir, Options{}))
+	written, err := os.ReadFile(testFile)
+	require.NoError(t, err)
+	result := string(written)
+
+	assert.Contains(t, result, "func ParseStatus(s string) (Status, error) {")
+	assert.Contains(t, result, "case \"cancelled\", \"canceled\", \"CANCELED\":\n\t\treturn StatusCancelled, nil")
+	assert.Contains(t, result, "func (s *Status) UnmarshalText(text []byte) error {")
+	assert.Contains(t, result, "func (s *Status) UnmarshalJSON(j []byte) error {")
+	assert.Contains(t, result, "func (s *Status) Scan(value any) error {")
+	assert.Contains(t, result, `return fmt.Errorf("can't scan SQL NULL as example.Status")`)
+	// Marshaling always uses the canonical value, so no MarshalJSON or Value is needed
+	assert.NotContains(t, result, "MarshalJSON")
+	assert.NotContains(t, result, "Value()")
+	assert.NotContains(t, result, `"bytes"`)
+
+	// The generated ParseStatus function is replaced, not duplicated
+	require.NoError(t, Rewrite(tmpDir, Options{}))
+	secondPass, err := os.ReadFile(testFile)
+	require.NoError(t, err)
+	assert.Equal(t, result, string(secondPass))
+	require.NoError(t, ValidateRewrite(tmpDir, nil, false))
+}
+
+func TestRewrite_NullableAliases(t *testing.T) {
+	source := `package example
+
+type Status string //#enum
+
+const (
+	StatusNone      Status = ""          //#null
+	StatusCancelled Status = "cancelled" //#alias:"canceled"
+)
+`
+	result := rewriteSource(t, "status.go", source)
+
+	assert.Contains(t, result, "func ParseStatus(s string) (Status, error) {")
+	assert.Contains(t, result, "if bytes.Equal(j, []byte(\"null\")) {\n\t\t*s = StatusNone")
+	assert.Contains(t, result, "case nil:\n\t\t*s = StatusNone\n\t\treturn nil")
+	assert.Contains(t, result, "func (s Status) MarshalJSON() ([]byte, error) {")
+	assert.Contains(t, result, "func (s Status) Value() (driver.Value, error) {")
+	assert.Equal(t, 1, strings.Count(result, ") UnmarshalJSON("))
+	assert.Equal(t, 1, strings.Count(result, ") Scan("))
+}
+
+func TestRewrite_Normalize(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "status.go")
+
+	source := `package example
+
+type Status string //#enum,normalize=trim|lower
+
+const (
+	StatusActive   Status = "active"
+	StatusInactive Status = "inactive"
+)
+`
+	require.NoError(t, os.WriteFile(testFile, []byte(source), 0644))
+
+	require.NoError(t, Rewrite(tmpDir, Options{}))
+	written, err := os.ReadFile(testFile)
+	require.NoError(t, err)
+	result := string(written)
+
+	assert.Contains(t, result, "// The string is normalized before parsing with: trim, lower.\n")
+	// Unrecognized strings are decoded as is like without normalization
+	assert.Contains(t, result, "\treturn Status(s), nil\n}")
+	assert.NotContains(t, result, `fmt.Errorf("invalid value %q`)
+	typeCheckSource(t, "status.go", result)
+	assert.Contains(t, result, "switch strings.ToLower(strings.TrimSpace(s)) {")
+	assert.Contains(t, result, "case \"active\":\n\t\treturn StatusActive, nil")
+	assert.Contains(t, result, `"strings"`)
+	assert.NotContains(t, result, "aliases of legacy spellings")
+	assert.Contains(t, result, "func (s *Status) UnmarshalJSON(j []byte) error {")
+
+	require.NoError(t, ValidateRewrite(tmpDir, nil, false))
+}
+
+func TestRewrite_Unknown(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "status.go")
+
+	source := `package example
+
+type Status string //#enum
+
+const (
+	StatusActive  Status = "active"
+	StatusUnknown Status = "unknown" //#unknown
+)
+
+type Priority int //#enum
+
+const (
+	PriorityNull    Priority = 0 //#null
+	PriorityLow     Priority = 1
+	PriorityUnknown Priority = -1 //#unknown
+)
+
+type Level int //#enum
+
+const (
+	LevelLow   Level = 1
+	LevelOther Level = -1 //#unknown:keep
+)
+`
+	require.NoError(t, os.WriteFile(testFile, []byte(source), 0644))
+
+	require.NoError(t, Rewrite(tmpDir, Options{}))
+	written, err := os.ReadFile(testFile)
+	require.NoError(t, err)
+	result := string(written)
+
+	// String enums map unrecognized input in the parse function
+	assert.Contains(t, result, "func ParseStatus(s string) (Status, error) {")
+	assert.Contains(t, result, "\t}\n\treturn StatusUnknown, nil\n}")
+	assert.Contains(t, result, "func (s Status) IsUnknown() bool {\n\treturn s == StatusUnknown || !s.Valid()\n}")
+
+	// Integer enums map invalid values after decoding
+	assert.Contains(t, result, "func (p *Priority) UnmarshalJSON(j []byte) error {")
+	assert.Contains(t, result, "func (p *Priority) Scan(value any) error {")
+	assert.Equal(t, 2, strings.Count(result, "if !p.Valid() {\n\t\t*p = PriorityUnknown\n\t}"))
+
+	// Integers are kept as is without extra decoding methods
+	assert.Contains(t, result, "func (l Level) IsUnknown() bool {")
+	assert.NotContains(t, result, "func (l *Level) UnmarshalJSON(")
+	assert.NotContains(t, result, "func (l *Level) Scan(")
+
+	require.NoError(t, ValidateRewrite(tmpDir, nil, false))
+}
+
+func TestRewrite_Default(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "priority.go")
+
+	source := `package example
+
+type Priority int //#enum,jsonschema
+
+const (
+	PriorityLow    Priority = 1
+	PriorityNormal Priority = 2 //#default
+)
+`
+	require.NoError(t, os.WriteFile(testFile, []byte(source), 0644))
+
+	require.NoError(t, Rewrite(tmpDir, Options{}))
+	written, err := os.ReadFile(testFile)
+	require.NoError(t, err)
+	result := string(written)
+
+	assert.Contains(t, result, "func DefaultPriority() Priority {\n\treturn PriorityNormal\n}")
+	assert.Contains(t, result, "func (p *Priority) SetDefault() {\n\t*p = PriorityNormal\n}")
+	assert.Contains(t, result, "Default: PriorityNormal,")
+	// Without //#default:empty no parse function is needed
+	assert.NotContains(t, result, "ParsePriority")
+
+	require.NoError(t, ValidateRewrite(tmpDir, nil, false))
+}
+
+func TestRewrite_Deprecated(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "status.go")
+
+	source := `package example
+
+type Status string //#enum,jsonschema,ondeprecated
+
+const (
+	StatusActive Status = "active"
+	// Deprecated: use StatusActive
+	StatusOld Status = "old"
+)
+`
+	require.NoError(t, os.WriteFile(testFile, []byte(source), 0644))
+
+	require.NoError(t, Rewrite(tmpDir, Options{}))
+	written, err := os.ReadFile(testFile)
+	require.NoError(t, err)
+	result := string(written)
+
+	assert.Contains(t, result, "func (Status) Enums() []Status {\n\treturn []Status{\n\t\tStatusActive,\n\t}\n}")
+	assert.Contains(t, result, "func (Status) EnumStrings() []string {\n\treturn []string{\n\t\t\"active\",\n\t}\n}")
+	assert.Contains(t, result, "func (Status) AllIncludingDeprecated() []Status {\n\treturn []Status{\n\t\tStatusActive,\n\t\tStatusOld,\n\t}\n}")
+	assert.Contains(t, result, "func (s Status) IsDeprecated() bool {\n\tswitch s {\n\tcase StatusOld:\n\t\treturn true")
+	assert.Contains(t, result, "var OnDeprecatedStatus func(Status)")
+	assert.Contains(t, result, "case \"old\":\n\t\tif OnDeprecatedStatus != nil {\n\t\t\tOnDeprecatedStatus(StatusOld)\n\t\t}\n\t\treturn StatusOld, nil")
+	assert.Contains(t, result, `{Const: "old", Description: "Deprecated: use StatusActive", Deprecated: true},`)
+	// Valid still accepts deprecated values of stored data
+	assert.Contains(t, result, "StatusOld:\n\t\treturn true")
+
+	// The generated variable is replaced, not duplicated
+	require.NoError(t, Rewrite(tmpDir, Options{}))
+	secondPass, err := os.ReadFile(testFile)
+	require.NoError(t, err)
+	assert.Equal(t, result, string(secondPass))
+	assert.Equal(t, 1, strings.Count(result, "var OnDeprecatedStatus"))
+	require.NoError(t, ValidateRewrite(tmpDir, nil, false))
+}
+
+func TestRewrite_OnDeprecatedWithoutDeprecatedValues(t *testing.T) {
+	source := `package example
+
+type Priority int //#enum,ondeprecated,strict
+
+const (
+	PriorityLow  Priority = 1
+	PriorityHigh Priority = 2
+)
+
+type Status string //#enum,ondeprecated
+
+const StatusActive Status = "active"
+`
+	result := rewriteSource(t, "types.go", source)
+
+	// Without deprecated values there is no IsDeprecated method to call
+	assert.NotContains(t, result, "IsDeprecated")
+	assert.Contains(t, result, "var OnDeprecatedPriority func(Priority)")
+	assert.Contains(t, result, "var OnDeprecatedStatus func(Status)")
+	assert.NotContains(t, result, "func ParseStatus(")
+	typeCheckSource(t, "types.go", result)
+}
+
+func TestRewrite_Registry(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "status.go")
+
+	source := `package example
+
+type Status int //#enum,registry
+
+const (
+	StatusNull   Status = 0 //#null
+	StatusActive Status = 1 // Active orders
+)
+
+func init() {
+	println("hand-written")
+}
+`
+	require.NoError(t, os.WriteFile(testFile, []byte(source), 0644))
+
+	require.NoError(t, Rewrite(tmpDir, Options{}))
+	written, err := os.ReadFile(testFile)
+	require.NoError(t, err)
+	result := string(written)
+
+	assert.Contains(t, result, `"github.com/ungerik/go-enum/enumreg"`)
+	assert.Contains(t, result, "enumreg.Register(enumreg.Definition[Status]{")
+	assert.Contains(t, result, "Names: []string{\n\t\t\t\"StatusNull\",\n\t\t\t\"StatusActive\",\n\t\t},")
+	assert.Contains(t, result, "Values: []Status{\n\t\t\tStatusNull,\n\t\t\tStatusActive,\n\t\t},")
+	assert.Contains(t, result, "Nullable: true,\n\t\tNull:     StatusNull,")
+	assert.Contains(t, result, "Descriptions: []string{\n\t\t\t\"\",\n\t\t\t\"Active orders\",\n\t\t},")
+	assert.Contains(t, result, `println("hand-written")`)
+
+	// Generated init is replaced and the hand-written one kept
+	require.NoError(t, Rewrite(tmpDir, Options{}))
+	secondPass, err := os.ReadFile(testFile)
+	require.NoError(t, err)
+	assert.Equal(t, result, string(secondPass))
+	assert.Equal(t, 2, strings.Count(result, "func init() {"))
+	require.NoError(t, ValidateRewrite(tmpDir, nil, false))
+}
+
+func TestRewrite_Assert(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "priority.go")
+
+	source := `package example
+
+type Priority int //#enum,assert
+
+const (
+	PriorityLow  Priority = 1
+	PriorityHigh Priority = 2
+)
+`
+	require.NoError(t, os.WriteFile(testFile, []byte(source), 0644))
+
+	require.NoError(t, Rewrite(tmpDir, Options{}))
+	written, err := os.ReadFile(testFile)
+	require.NoError(t, err)
+	result := string(written)
+
+	assert.Contains(t, result, `"github.com/ungerik/go-enum/enum"`)
+	assert.Contains(t, result, "var _ enum.Enum[Priority] = Priority(0)")
+
+	require.NoError(t, Rewrite(tmpDir, Options{}))
+	secondPass, err := os.ReadFile(testFile)
+	require.NoError(t, err)
+	assert.Equal(t, result, string(secondPass))
+	require.NoError(t, ValidateRewrite(tmpDir, nil, false))
+}
+
+func TestRewrite_Flag(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "flags.go")
+
+	source := `package example
+
+type Status string //#enum,flag,pflag
+
+const (
+	StatusActive Status = "active"
+	StatusDone   Status = "done"
+)
+
+type Level int //#enum,flag
+
+const (
+	LevelLow  Level = 1
+	LevelHigh Level = 2
+)
+
+type Priority uint8 //#enum,flag
+
+const PriorityLow Priority = 1
+`
+	require.NoError(t, os.WriteFile(testFile, []byte(source), 0644))
+
+	require.NoError(t, Rewrite(tmpDir, Options{}))
+	written, err := os.ReadFile(testFile)
+	require.NoError(t, err)
+	result := string(written)
+
+	// String enums
+	assert.Contains(t, result, "func (s *Status) Set(str string) error {\n\tvalue := Status(str)\n\tif !value.Valid() {")
+	assert.Contains(t, result, "func (Status) Type() string {\n\treturn \"Status\"\n}")
+	assert.Contains(t, result, "func StatusVar(fs *flag.FlagSet, p *Status, name string, value Status, usage string) {")
+	assert.Contains(t, result, "func StatusVarP(fs *pflag.FlagSet, p *Status, name, shorthand string, value Status, usage string) {")
+	assert.Contains(t, result, "func CompleteStatus(toComplete string) []string {")
+	assert.Contains(t, result, `"github.com/spf13/pflag"`)
+
+	// Integer enums get a String method and parse integers
+	assert.Contains(t, result, "func (l Level) String() string {\n\treturn fmt.Sprint(int(l))\n}")
+	assert.Contains(t, result, "i, err := strconv.ParseInt(str, 10, 0)")
+	// with the bit size of the underlying type so that values don't overflow
+	assert.Contains(t, result, "i, err := strconv.ParseUint(str, 10, 8)")
+	assert.Contains(t, result, "func LevelVar(fs *flag.FlagSet, p *Level, name string, value Level, usage string) {")
+	assert.NotContains(t, result, "LevelVarP")
+	assert.NotContains(t, result, "CompleteLevel")
+	assert.Equal(t, 3, strings.Count(result, ") Set(str string) error {"))
+
+	require.NoError(t, ValidateRewrite(tmpDir, nil, false))
+}
+
+func TestRewrite_Slog(t *testing.T) {
+	source := `package example
+
+type Priority int //#enum,slog
+
+const (
+	PriorityNull Priority = 0 //#null //#label:"Not set"
+	PriorityLow  Priority = 1
+)
+`
+	result := rewriteSource(t, "priority.go", source)
+
+	assert.Contains(t, result, `"log/slog"`)
+	assert.Contains(t, result, "func (p Priority) LogValue() slog.Value {")
+	assert.Contains(t, result, "case PriorityNull:\n\t\treturn slog.StringValue(\"Not set\")")
+	assert.Contains(t, result, "case PriorityLow:\n\t\treturn slog.StringValue(\"PriorityLow\")")
+	assert.Contains(t, result, "slog.Any(\"value\", int(p)),\n\t\tslog.Bool(\"invalid\", true),")
+}
+
+func TestRewrite_PGX(t *testing.T) {
+	source := `package example
+
+type Status string //#enum,pgx
+
+const (
+	StatusNull   Status = "" //#null
+	StatusActive Status = "active"
+)
+
+type Color string //#enum,pgx,nocase
+
+const (
+	ColorRed  Color = "red"
+	ColorBlue Color = "blue"
+)
+
+type Priority uint8 //#enum,pgx
+
+const (
+	PriorityLow     Priority = 1
+	PriorityUnknown Priority = 0 //#unknown
+)
+`
+	result := rewriteSource(t, "types.go", source)
+
+	assert.Contains(t, result, `"github.com/jackc/pgx/v5/pgtype"`)
+
+	// String enums map SQL NULL to the null value and validate
+	assert.Contains(t, result, "func (s *Status) ScanText(v pgtype.Text) error {\n\tif !v.Valid {\n\t\t*s = StatusNull\n\t\treturn nil\n\t}\n\tvalue := Status(v.String)\n\tif err := value.Validate(); err != nil {")
+	assert.Contains(t, result, "func (s Status) TextValue() (pgtype.Text, error) {\n\tif s == StatusNull {\n\t\treturn pgtype.Text{}, nil\n\t}")
+	assert.Contains(t, result, "m.RegisterType(&pgtype.Type{Name: \"status\", OID: oid, Codec: &pgtype.EnumCodec{}})")
+	assert.Contains(t, result, "m.RegisterDefaultPgType(Status(\"\"), \"status\")")
+
+	// Enums with a parse function scan with it, non-nullable enums reject NULL
+	assert.Contains(t, result, "value, err := ParseColor(v.String)")
+	assert.Contains(t, result, `return fmt.Errorf("can't scan SQL NULL as example.Color")`)
+
+	// Integer enums check the range and map unrecognized values to the unknown value
+	assert.Contains(t, result, "func (p *Priority) ScanInt64(v pgtype.Int8) error {")
+	assert.Contains(t, result, "if int64(value) != v.Int64 {")
+	assert.Contains(t, result, "if !value.Valid() {\n\t\tvalue = PriorityUnknown\n\t}")
+	assert.Contains(t, result, "func (p Priority) Int64Value() (pgtype.Int8, error) {")
+	assert.Contains(t, result, "func RegisterPriorityPgType(m *pgtype.Map, oid uint32) {")
+	assert.Contains(t, result, "Codec: pgtype.Int2Codec{}")
+}
+
+func TestRewrite_Strict(t *testing.T) {
+	source := `package example
+
+type Status string //#enum,strict
+
+const (
+	StatusActive   Status = "active"
+	StatusInactive Status = "inactive"
+)
+
+type Priority int //#enum,strict
+
+const (
+	PriorityLow  Priority = 1
+	PriorityHigh Priority = 2
+)
+`
+	result := rewriteSource(t, "types.go", source)
+
+	// String enums are decoded with the parse function
+	assert.Contains(t, result, "func ParseStatus(s string) (Status, error) {")
+	assert.Contains(t, result, `return "", fmt.Errorf("invalid value %q for type example.Status", s)`)
+	assert.Contains(t, result, "func (s *Status) UnmarshalJSON(j []byte) error {")
+	assert.Contains(t, result, "func (s *Status) Scan(value any) error {")
+
+	// Integer enums validate after decoding
+	assert.Contains(t, result, "// UnmarshalJSON implements encoding/json.Unmarshaler\n// returning an error for invalid values\nfunc (p *Priority) UnmarshalJSON(j []byte) error {")
+	assert.Contains(t, result, "func (p *Priority) Scan(value any) error {")
+	assert.Equal(t, 2, strings.Count(result, "if err := p.Validate(); err != nil {\n\t\treturn err\n\t}"))
+}
+
+func TestRewrite_FlagUnknown(t *testing.T) {
+	source := `package example
+
+type Status string //#enum,flag,normalize=lower
+
+const (
+	StatusActive Status = "active"
+	StatusOther  Status = "other" //#unknown //#alias:"misc"
+)
+`
+	result := rewriteSource(t, "status.go", source)
+	typeCheckSource(t, "status.go", result)
+
+	// Only the value and aliases of the unknown constant set it
+	assert.Contains(t, result, "// Reject typos that ParseStatus maps to StatusOther\n\tif value == StatusOther && strings.ToLower(str) != \"other\" && strings.ToLower(str) != \"misc\" {\n\t\treturn fmt.Errorf(\"invalid value %q for type example.Status, expected one of: %s\"")
+
+	// Keeping unknown values fails Valid instead
+	result = rewriteSource(t, "status.go", strings.Replace(source, "//#unknown", "//#unknown:keep", 1))
+	assert.NotContains(t, result, "Reject typos")
+}
+
+func TestRewrite_EnumStringsValues(t *testing.T) {
+	source := `package example
+
+import "math"
+
+const base = 4
+
+type Kind int //#enum,flag
+
+const (
+	KindA   Kind = iota + 1
+	KindB   Kind = base + 1
+	KindC   Kind = 0x10
+	KindMax Kind = math.MaxInt8
+)
+
+type Mode string //#enum
+
+const (
+	ModeFast Mode = "fast"
+	ModeSlow Mode = Mode(prefix + "slow")
+)
+
+const prefix = "very_"
+`
+	result := rewriteSource(t, "kind.go", source)
+	typeCheckSource(t, "kind.go", result)
+
+	// The type-checked values instead of the literals,
+	// imported constants are converted at runtime
+	assert.Contains(t, result, "func (Kind) EnumStrings() []string {\n\treturn []string{\n\t\t\"1\",\n\t\t\"5\",\n\t\t\"16\",\n\t\tstrconv.FormatInt(int64(KindMax), 10),\n\t}\n}")
+	assert.Contains(t, result, "func (Mode) EnumStrings() []string {\n\treturn []string{\n\t\t\"fast\",\n\t\t\"very_slow\",\n\t}\n}")
+	// Used for the flag usage and Set error
+	assert.Contains(t, result, `strings.Join(value.EnumStrings(), ", ")`)
+	assert.NotContains(t, result, "iota + 1\"")
+}
+
+func TestRewrite_IntStringName(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "priority.go")
+
+	source := `package example
+
+type Priority int //#enum,string=name,flag
+
+const (
+	PriorityLow  Priority = 1
+	PriorityHigh Priority = 2
+)
+`
+	require.NoError(t, os.WriteFile(testFile, []byte(source), 0644))
+
+	var output bytes.Buffer
+	require.NoError(t, Rewrite(tmpDir, Options{ResultOut: &output}))
+	result := output.String()
+
+	assert.Contains(t, result, "func (p Priority) String() string {\n\tswitch p {\n\tcase PriorityLow:\n\t\treturn \"PriorityLow\"")
+	assert.Contains(t, result, "return fmt.Sprint(int(p))")
+	// Set accepts the names returned by String
+	assert.Contains(t, result, "for _, value := range Priority(0).Enums() {\n\t\tif value.String() == str {")
+	// EnumStrings and the flag usage list the same names
+	assert.Contains(t, result, "func (Priority) EnumStrings() []string {\n\treturn []string{\n\t\t\"PriorityLow\",\n\t\t\"PriorityHigh\",")
+
+	require.NoError(t, os.WriteFile(testFile, []byte(strings.Replace(source, "//#enum,string=name,flag", "//#enum,string=label", 1)), 0644))
+	err := Rewrite(tmpDir, Options{ResultOut: &output})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown intString "label", expected number or name`)
+}
+
+func TestRewrite_Config(t *testing.T) {
+	tmpDir := t.TempDir()
+	legacyDir := filepath.Join(tmpDir, "legacy")
+	require.NoError(t, os.MkdirAll(legacyDir, 0755))
+
+	config := `options: [description, nocase]
+layout: file
+strict: true
+intString: name
+packages:
+  legacy:
+    layout: inline
+    options: []
+`
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module example.com/test\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ConfigFileName), []byte(config), 0644))
+
+	source := `package example
+
+import "fmt"
+
+// Status of an order
+type Status string //#enum
+
+const (
+	// Active orders
+	StatusActive Status = "active"
+	StatusClosed Status = "closed"
+)
+
+type Priority int //#enum
+
+const (
+	PriorityLow  Priority = 1
+	PriorityHigh Priority = 2
+)
+
+// Valid indicates if s is any of the valid values for Status
+func (s Status) Valid() bool {
+	return fmt.Sprint(s) != ""
+}
+
+func Other() {}
+`
+	statusFile := filepath.Join(tmpDir, "types.go")
+	require.NoError(t, os.WriteFile(statusFile, []byte(source), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(legacyDir, "types.go"), []byte(strings.Replace(source, "package example", "package legacy", 1)), 0644))
+
+	require.Error(t, Rewrite(tmpDir+"/...", Options{Validate: true}))
+	require.NoError(t, Rewrite(tmpDir+"/...", Options{}))
+
+	// Generated methods are moved to a separate file
+	rewritten, err := os.ReadFile(statusFile)
+	require.NoError(t, err)
+	assert.NotContains(t, string(rewritten), "Valid()")
+	assert.NotContains(t, string(rewritten), `import "fmt"`, "unused import removed")
+	assert.Contains(t, string(rewritten), "func Other() {}")
+
+	generated, err := os.ReadFile(filepath.Join(tmpDir, "types"+GeneratedFileSuffix))
+	require.NoError(t, err)
+	result := string(generated)
+	assert.True(t, strings.HasPrefix(result, "// Code generated by go-enum from types.go. DO NOT EDIT.\n\npackage example\n"))
+	assert.Less(t, strings.Index(result, "func (s Status) Valid() bool"), strings.Index(result, "func (p Priority) Valid() bool"))
+	assert.Contains(t, result, "func (s Status) Description() string {")
+	assert.Contains(t, result, "func (p Priority) Description() string {")
+	assert.Contains(t, result, "switch strings.ToLower(s) {")
+	assert.Contains(t, result, "return \"PriorityLow\"")
+	assert.Contains(t, result, "if err := p.Validate(); err != nil {")
+
+	// The package override keeps the methods inline without the options
+	legacy, err := os.ReadFile(filepath.Join(legacyDir, "types.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(legacy), "func (s Status) Valid() bool {\n\tswitch s {")
+	assert.NotContains(t, string(legacy), "Description()")
+	assert.NotContains(t, string(legacy), "strings.ToLower")
+	assert.Contains(t, string(legacy), "if err := p.Validate(); err != nil {")
+	assert.NoFileExists(t, filepath.Join(legacyDir, "types"+GeneratedFileSuffix))
+
+	// Up to date
+	require.NoError(t, Rewrite(tmpDir+"/...", Options{Validate: true}))
+
+	// An empty Config ignores the configuration file
+	var output bytes.Buffer
+	require.NoError(t, Rewrite(statusFile, Options{ResultOut: &output, Config: &Config{}}))
+	assert.Contains(t, output.String(), "func (s Status) Valid() bool {")
+
+	// Hand-written files are not overwritten
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "types"+GeneratedFileSuffix), []byte("package example\n"), 0644))
+	err = Rewrite(tmpDir, Options{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "was not generated by go-enum")
+}
+
+// rewriteSource returns the rewritten source of
+// a single file with the name fileName in memory.
+// typeCheckSource fails the test if the source doesn't compile.
+func typeCheckSource(t *testing.T, fileName, source string) {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, fileName, source, 0)
+	require.NoError(t, err)
+	config := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	_, err = config.Check("example", fset, []*ast.File{file}, nil)
+	require.NoError(t, err)
+}
+
+func rewriteSource(t *testing.T, fileName, source string) string {
+	t.Helper()
+	rewritten, err := RewriteSources(context.Background(), map[string][]byte{fileName: []byte(source)}, Options{})
+	require.NoError(t, err)
+	return string(rewritten[fileName])
+}
+
+func TestRewriteSources(t *testing.T) {
+	sources := map[string][]byte{
+		"go.mod":             []byte("module example.com/test\n"),
+		"models/status.go":   []byte(runTestSource),
+		"models/other.go":    []byte("package example\n"),
+		"models/main/cmd.go": []byte("package main\n\ntype Mode int //#enum\n"),
+		"api/color.go":       []byte("package api\n\ntype Color int //#enum,strict\n\nconst ColorRed Color = 1\n"),
+	}
+	config := &Config{Layout: LayoutFile}
+
+	rewritten, err := RewriteSources(context.Background(), sources, Options{Config: config})
+	require.NoError(t, err)
+
+	// Unchanged files are returned as is
+	assert.Equal(t, sources["models/status.go"], rewritten["models/status.go"])
+	assert.Equal(t, sources["models/main/cmd.go"], rewritten["models/main/cmd.go"])
+	assert.Contains(t, string(rewritten["models/status"+GeneratedFileSuffix]), "func (s Status) Valid() bool {")
+	assert.Contains(t, string(rewritten["api/color"+GeneratedFileSuffix]), "// Code generated by go-enum from color.go. DO NOT EDIT.\n\npackage api\n")
+	assert.NotContains(t, rewritten, "models/other"+GeneratedFileSuffix)
+	assert.NotContains(t, rewritten, "models/main/cmd"+GeneratedFileSuffix)
+
+	// Rewriting the result again doesn't change anything
+	result, err := Run(context.Background(), Options{FS: mapFS(rewritten), Path: "./...", Config: config, Validate: true})
+	require.NoError(t, err)
+	assert.Len(t, result.Files, 4)
+	assert.Empty(t, result.Changed())
+
+	// Only some files
+	result, err = Run(context.Background(), Options{FS: mapFS(sources), Files: []string{"/api/color.go"}})
+	require.NoError(t, err)
+	require.Len(t, result.Files, 1)
+	assert.Equal(t, "api/color.go", result.Files[0].Path)
+	assert.Equal(t, "api", result.Files[0].Enums[0].Dir)
+
+	_, err = Run(context.Background(), Options{FS: mapFS(sources), Path: "../outside"})
+	require.Error(t, err)
+}
+
+func mapFS(files map[string][]byte) fstest.MapFS {
+	fsys := make(fstest.MapFS)
+	for name, data := range files {
+		fsys[name] = &fstest.MapFile{Data: data}
+	}
+	return fsys
+}
@@ -0,0 +1,542 @@
+package enums
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"io"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing/fstest"
+	"time"
+
+	"github.com/ungerik/go-astvisit"
+)
+
+// Options for Run and Rewrite.
+type Options struct {
+	// Path is the directory or file path to process, "." if empty.
+	// A directory path ending with "..." also processes all sub-directories.
+	// Ignored by Rewrite, which has its own path argument.
+	Path string
+	// Files restricts the processing to these source files.
+	// If Path is empty, only these files are processed,
+	// files of main packages are skipped.
+	Files []string
+	// FS is the file system that source files are read from instead of
+	// the local file system. Path and Files are slash separated paths
+	// relative to its root, no files are written, and the configuration
+	// file is not looked up. See also RewriteSources.
+	FS fs.FS
+	// VerboseOut receives progress information, nil to disable
+	VerboseOut io.Writer
+	// ResultOut receives the rewritten source code instead of
+	// writing it to the files, nil to write the files
+	ResultOut io.Writer
+	// DryRun computes the Result without writing any files
+	DryRun bool
+	// Debug inserts debug comments in the generated code
+	Debug bool
+	// Validate checks if enum methods are missing or outdated without
+	// modifying files. Any missing or outdated methods are reported
+	// as Result.Findings and result in an error.
+	Validate bool
+	// Config is the project configuration. If nil, the ConfigFileName
+	// file in the module root of the path is used if it exists,
+	// see FindConfig. Use an empty Config to ignore the file.
+	Config *Config
+	// Logger receives a record for every processed
+	// and written file, nil to disable
+	Logger *slog.Logger
+	// Templates are the user-defined templates for the
+	// //#enum,template=name option. If nil, the templates
+	// in the Templates directory of the Config are loaded
+	// for every Run, see LoadTemplates.
+	Templates Templates
+}
+
+// Result of Run.
+type Result struct {
+	// Files are the processed source files with enums
+	// and the generated files of the LayoutFile layout
+	// in the order they were processed
+	Files []*FileResult
+	// Findings are the missing or outdated generated declarations
+	// and files found with Options.Validate
+	Findings []Finding
+	// Duration of the Run
+	Duration time.Duration
+}
+
+// Enums returns the enums of all Files.
+func (r *Result) Enums() []*Enum {
+	var enums []*Enum
+	for _, file := range r.Files {
+		enums = append(enums, file.Enums...)
+	}
+	return enums
+}
+
+// Changed returns the Files that were or would be changed.
+func (r *Result) Changed() []*FileResult {
+	var changed []*FileResult
+	for _, file := range r.Files {
+		if file.Changed() {
+			changed = append(changed, file)
+		}
+	}
+	return changed
+}
+
+// FileResult is the result of Run for a single file.
+type FileResult struct {
+	// Path is the absolute path of the file,
+	// or the path in Options.FS
+	Path string
+	// Enums are the enums found in the file sorted by line,
+	// nil for generated files of the LayoutFile layout
+	Enums []*Enum
+	// Source is the content of the file before Run,
+	// nil if the file did not exist
+	Source []byte
+	// Output is the rewritten content of the file,
+	// nil if the file is unchanged
+	Output []byte
+	// Duration of processing the file
+	Duration time.Duration
+
+	// findHash identifies the enums found in the file
+	// with their generated code and existing declarations
+	findHash string
+}
+
+// Changed returns true if the file was or would be changed.
+func (f *FileResult) Changed() bool {
+	return f.Output != nil
+}
+
+// Finding is a missing or outdated generated declaration
+// or file reported with Options.Validate.
+type Finding struct {
+	Pos     token.Position
+	Message string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s", f.Pos, f.Message)
+}
+
+// Run generates or updates the methods of the enums at options.Path
+// or in options.Files like Rewrite, and returns the processed files.
+//
+// The files are only written after all files were processed without error,
+// and not at all with options.DryRun or options.Validate. The context
+// cancels processing between files. With options.Validate an error is
+// returned together with the Result if there are any Findings.
+func Run(ctx context.Context, options Options) (*Result, error) {
+	start := time.Now()
+	paths := []string{options.Path}
+	switch {
+	case options.Path == "" && len(options.Files) > 0:
+		paths = options.Files
+	case options.Path == "":
+		paths[0] = "."
+	}
+	sfs := sourceFS{fsys: options.FS}
+	var onlyFiles map[string]bool
+	if options.Path != "" && len(options.Files) > 0 {
+		onlyFiles = make(map[string]bool, len(options.Files))
+		for _, file := range options.Files {
+			absFile, err := sfs.abs(file)
+			if err != nil {
+				return nil, err
+			}
+			onlyFiles[absFile] = true
+		}
+	}
+	config := options.Config
+	if config == nil && options.FS == nil {
+		var err error
+		config, err = FindConfig(paths[0])
+		if err != nil {
+			return nil, err
+		}
+	}
+	templates, err := runTemplates(options, config)
+	if err != nil {
+		return nil, err
+	}
+	r := &runner{
+		options:   options,
+		sfs:       sfs,
+		config:    config,
+		templates: templates,
+		logger:    options.Logger,
+		result:    new(Result),
+	}
+	if r.logger == nil {
+		r.logger = slog.New(slog.DiscardHandler)
+	}
+
+	for _, p := range paths {
+		err := sfs.walkFiles(p, func(fset *token.FileSet, pkg *ast.Package, astFile *ast.File, filePath string) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if onlyFiles != nil && !onlyFiles[filePath] {
+				return nil
+			}
+			return r.rewriteFile(fset, pkg, astFile, filePath)
+		})
+		if err != nil && options.Path == "" && errors.Is(err, astvisit.ErrPackageNotFound) {
+			// One of the Files is in a main package
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := r.writeFiles(); err != nil {
+		return nil, err
+	}
+	r.result.Duration = time.Since(start)
+	if len(r.result.Findings) > 0 {
+		return r.result, fmt.Errorf("found %d missing or outdated enum method(s)", len(r.result.Findings))
+	}
+	return r.result, nil
+}
+
+type runner struct {
+	options   Options
+	sfs       sourceFS
+	config    *Config
+	templates Templates
+	logger    *slog.Logger
+	result    *Result
+}
+
+// rewriteFile adds the FileResult of filePath and its generated file.
+func (r *runner) rewriteFile(fset *token.FileSet, pkg *ast.Package, astFile *ast.File, filePath string) error {
+	// ast.Print(fset, astFile)
+	// return nil
+
+	start := time.Now()
+	if err := astvisit.FprintfVerbose(r.options.VerboseOut, "parsing file: %s\n", filePath); err != nil {
+		return err
+	}
+	fileConfig := r.config.ForDir(r.sfs.dir(filePath))
+	enums, err := find(fset, pkg, astFile, fileConfig, r.templates)
+	if err != nil {
+		return err
+	}
+	if len(enums) == 0 {
+		return nil
+	}
+	layoutFile := fileConfig != nil && fileConfig.Layout == LayoutFile
+
+	var (
+		sorted       = sortedByLine(enums)
+		replacements astvisit.NodeReplacements
+		imports      = make(astvisit.Imports)
+		generated    bytes.Buffer
+		findHash     = sha256.New()
+	)
+	for _, enum := range sorted {
+		methods, err := enum.generate(imports)
+		if err != nil {
+			return err
+		}
+		findHash.Write(methods)
+		fmt.Fprintln(findHash, enum.existingDeclNames())
+
+		debugID := "Replacement for " + enum.Type
+		known := enum.knownDecls()
+		if layoutFile {
+			// Move all methods to the generated file
+			generated.Write(methods)
+			for _, methodWithDoc := range known {
+				replacements.AddRemoval(methodWithDoc, debugID)
+			}
+			continue
+		}
+		if len(known) == 0 {
+			// No existing methods to replace,
+			// insert new methods after last enum declaration
+			replacements.AddInsertAfter(enum.LastEnumDecl, methods, debugID)
+			continue
+		}
+		for i, methodWithDoc := range known {
+			if i == 0 {
+				// Replace the first existing method with all new ones
+				replacements.AddReplacement(methodWithDoc, methods, debugID)
+			} else {
+				// Remove all further existing methods
+				replacements.AddRemoval(methodWithDoc, debugID)
+			}
+		}
+	}
+
+	source, err := r.sfs.readFile(filePath)
+	if err != nil {
+		return err
+	}
+	fileResult := &FileResult{Path: filePath, Enums: sorted, Source: source, findHash: string(findHash.Sum(nil))}
+	r.result.Files = append(r.result.Files, fileResult)
+
+	var generatedImports astvisit.Imports
+	if layoutFile {
+		generatedResult, err := r.generatedFile(strings.TrimSuffix(filePath, ".go")+GeneratedFileSuffix, pkg.Name, path.Base(filepath.ToSlash(filePath)), generated.Bytes(), imports)
+		if err != nil {
+			return err
+		}
+		r.result.Files = append(r.result.Files, generatedResult)
+		// The source file doesn't need the imports anymore
+		generatedImports, imports = imports, nil
+	}
+
+	fileResult.Output, err = r.rewriteSource(fset, source, replacements, imports, generatedImports)
+	if err != nil {
+		return err
+	}
+	fileResult.Duration = time.Since(start)
+	r.logger.Debug("processed file", "path", filePath, "enums", len(sorted), "changed", fileResult.Changed(), "duration", fileResult.Duration)
+	return nil
+}
+
+// rewriteSource returns the source with the replacements applied,
+// or nil if that would not change the source.
+// In validate mode, the replacements are added as findings.
+func (r *runner) rewriteSource(fset *token.FileSet, source []byte, replacements astvisit.NodeReplacements, imports, generatedImports astvisit.Imports) ([]byte, error) {
+	if len(replacements) == 0 {
+		return nil, nil
+	}
+	rewritten, err := replacements.Apply(fset, source)
+	if err != nil {
+		return nil, err
+	}
+	rewritten, err = astvisit.FormatFileWithImports(fset, rewritten, imports)
+	if err != nil {
+		return nil, err
+	}
+	if generatedImports != nil {
+		rewritten, err = removeUnusedImports(rewritten, generatedImports)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Drop replacements when they produce no semantic change. Both
+	// the source and the rewritten output are normalized via
+	// FormatFileWithImports before comparison so that purely
+	// cosmetic differences (import reordering, whitespace) cancel
+	// out instead of being reported as missing/outdated methods.
+	// Dropping the replacements when nothing changes also avoids
+	// rewriting the file in normal mode, so a file with already
+	// up-to-date methods stays byte-identical even when its imports
+	// were not in the order goimports would produce.
+	formattedSource, err := astvisit.FormatFileWithImports(fset, source, imports)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(formattedSource, rewritten) {
+		return nil, nil
+	}
+
+	if r.options.Validate {
+		for _, repl := range replacements {
+			var pos token.Position
+			if repl.Node != nil {
+				pos = fset.Position(repl.Node.Pos())
+			}
+			desc := repl.DebugID
+			if desc == "" {
+				desc = "enum methods"
+			}
+			r.result.Findings = append(r.result.Findings, Finding{Pos: pos, Message: "missing or outdated " + desc})
+		}
+	}
+
+	if r.options.Debug {
+		return replacements.DebugApply(fset, source)
+	}
+	if bytes.Equal(source, rewritten) {
+		return nil, nil
+	}
+	return rewritten, nil
+}
+
+// generatedFile returns the FileResult for the file at filePath
+// of the LayoutFile layout with the methods generated for sourceFile.
+func (r *runner) generatedFile(filePath, pkgName, sourceFile string, methods []byte, imports astvisit.Imports) (*FileResult, error) {
+	existing, err := r.sfs.readFile(filePath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	content, err := generatedFileContent(existing, filePath, pkgName, sourceFile, methods, imports)
+	if err != nil {
+		return nil, err
+	}
+	fileResult := &FileResult{Path: filePath, Source: existing}
+	if existing == nil || !bytes.Equal(existing, content) {
+		fileResult.Output = content
+		if r.options.Validate {
+			r.result.Findings = append(r.result.Findings, Finding{Pos: token.Position{Filename: filePath}, Message: "missing or outdated generated file"})
+		}
+	}
+	return fileResult, nil
+}
+
+// writeFiles writes the changed files, or their
+// content to options.ResultOut if not nil.
+func (r *runner) writeFiles() error {
+	for _, file := range r.result.Files {
+		if !file.Changed() {
+			if err := astvisit.FprintfVerbose(r.options.VerboseOut, "no changes in file: %s\n", file.Path); err != nil {
+				return err
+			}
+			continue
+		}
+		if r.options.Validate || r.options.DryRun {
+			continue
+		}
+		if r.options.ResultOut != nil {
+			if _, err := r.options.ResultOut.Write(file.Output); err != nil {
+				return err
+			}
+			continue
+		}
+		if r.options.FS != nil {
+			// Can't write to an fs.FS
+			continue
+		}
+		if err := astvisit.FprintfVerbose(r.options.VerboseOut, "writing file: %s\n", file.Path); err != nil {
+			return err
+		}
+		if err := writeFile(file.Path, file.Output); err != nil {
+			return err
+		}
+		r.logger.Info("wrote file", "path", file.Path)
+	}
+	return nil
+}
+
+// RewriteSources is Run on in-memory Go source files keyed by slash
+// separated file paths, without accessing the local file system.
+// It returns the contents of all files after the rewrite, including
+// the generated files of the LayoutFile layout of options.Config.
+// If options.Path and options.Files are empty, all directories are processed.
+func RewriteSources(ctx context.Context, sources map[string][]byte, options Options) (map[string][]byte, error) {
+	fsys := make(fstest.MapFS, len(sources))
+	for name, source := range sources {
+		fsys[name] = &fstest.MapFile{Data: source, Mode: 0644}
+	}
+	options.FS = fsys
+	if options.Path == "" && len(options.Files) == 0 {
+		options.Path = "./..."
+	}
+	result, err := Run(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+	rewritten := maps.Clone(sources)
+	for _, file := range result.Changed() {
+		rewritten[file.Path] = file.Output
+	}
+	return rewritten, nil
+}
+
+// RewriteFileSource is Run on a single Go source file at filePath with the
+// content source instead of the content on disk, for example the unsaved
+// buffer of an editor. Other files are not read, and the configuration
+// of the module of filePath is applied if options.Config is nil.
+//
+// It returns the rewritten source, or source if there are no changes,
+// and the Result with the paths of the local file system.
+// No files are written: with the LayoutFile layout, the generated file
+// of filePath is a FileResult of the Result that the caller can write
+// if it changed. The Path, Files, FS, and ResultOut options are ignored.
+func RewriteFileSource(ctx context.Context, filePath string, source []byte, options Options) ([]byte, *Result, error) {
+	filePath, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	config := options.Config
+	if config == nil {
+		config, err = FindConfig(filePath)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	// The templates directory of the configuration
+	// is not available in the file system of the run
+	options.Templates, err = runTemplates(options, config)
+	if err != nil {
+		return nil, nil, err
+	}
+	// The file is put at its path relative to the configuration
+	// so that the package overrides of the configuration apply
+	rootDir := filepath.Dir(filePath)
+	if config != nil && config.Dir != "" {
+		if rel, err := filepath.Rel(config.Dir, filePath); err == nil && !strings.HasPrefix(rel, "..") {
+			rootDir = config.Dir
+		}
+		config = &Config{
+			Options:   config.Options,
+			Layout:    config.Layout,
+			Strict:    config.Strict,
+			IntString: config.IntString,
+			Packages:  config.Packages,
+		}
+	}
+	name, err := filepath.Rel(rootDir, filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	name = filepath.ToSlash(name)
+
+	fsys := fstest.MapFS{name: &fstest.MapFile{Data: source, Mode: 0644}}
+	// An existing generated file of the LayoutFile layout
+	// is compared with the generated content
+	generatedFilePath := strings.TrimSuffix(filePath, ".go") + GeneratedFileSuffix
+	if generated, err := os.ReadFile(generatedFilePath); err == nil {
+		fsys[strings.TrimSuffix(name, ".go")+GeneratedFileSuffix] = &fstest.MapFile{Data: generated, Mode: 0644}
+	}
+
+	options.Path = name
+	options.Files = nil
+	options.FS = fsys
+	options.ResultOut = nil
+	options.Config = config
+	result, err := Run(ctx, options)
+	if result == nil {
+		return nil, nil, err
+	}
+	// Map the paths in the file system back to the local file system
+	for _, file := range result.Files {
+		file.Path = filepath.Join(rootDir, filepath.FromSlash(file.Path))
+	}
+	for i := range result.Findings {
+		result.Findings[i].Pos.Filename = filepath.Join(rootDir, filepath.FromSlash(result.Findings[i].Pos.Filename))
+	}
+	if err != nil {
+		return nil, result, err
+	}
+
+	rewritten := source
+	for _, file := range result.Changed() {
+		if file.Path == filePath {
+			rewritten = file.Output
+		}
+	}
+	return rewritten, result, nil
+}
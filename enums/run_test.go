@@ -0,0 +1,208 @@
+package enums
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const runTestSource = `package example
+
+type Status string //#enum
+
+const (
+	StatusActive   Status = "active"
+	StatusInactive Status = "inactive"
+)
+`
+
+func TestRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	statusFile := filepath.Join(tmpDir, "status.go")
+	otherFile := filepath.Join(tmpDir, "other.go")
+	require.NoError(t, os.WriteFile(statusFile, []byte(runTestSource), 0644))
+	require.NoError(t, os.WriteFile(otherFile, []byte("package example\n"), 0644))
+
+	var logs bytes.Buffer
+	result, err := Run(context.Background(), Options{
+		Path:   tmpDir,
+		DryRun: true,
+		Logger: slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})),
+	})
+	require.NoError(t, err)
+
+	// Files without enums are not part of the result
+	require.Len(t, result.Files, 1)
+	file := result.Files[0]
+	assert.Equal(t, statusFile, file.Path)
+	assert.Equal(t, runTestSource, string(file.Source))
+	assert.True(t, file.Changed())
+	assert.Contains(t, string(file.Output), "func (s Status) Valid() bool {")
+	require.Len(t, file.Enums, 1)
+	assert.Equal(t, "Status", file.Enums[0].Type)
+	assert.Equal(t, file.Enums, result.Enums())
+	assert.Equal(t, result.Files, result.Changed())
+	assert.Empty(t, result.Findings)
+	assert.Positive(t, result.Duration)
+	assert.Contains(t, logs.String(), "msg=\"processed file\" path="+statusFile+" enums=1 changed=true")
+
+	// Dry run doesn't write files
+	source, err := os.ReadFile(statusFile)
+	require.NoError(t, err)
+	assert.Equal(t, runTestSource, string(source))
+
+	t.Run("Validate", func(t *testing.T) {
+		result, err := Run(context.Background(), Options{Path: tmpDir, Validate: true})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "found 1 missing or outdated enum method(s)")
+		require.NotNil(t, result)
+		require.Len(t, result.Findings, 1)
+		assert.Equal(t, statusFile, result.Findings[0].Pos.Filename)
+		assert.Equal(t, "missing or outdated Replacement for Status", result.Findings[0].Message)
+	})
+
+	t.Run("Write", func(t *testing.T) {
+		result, err := Run(context.Background(), Options{Path: tmpDir, Logger: slog.New(slog.NewTextHandler(&logs, nil))})
+		require.NoError(t, err)
+		source, err := os.ReadFile(statusFile)
+		require.NoError(t, err)
+		assert.Equal(t, string(result.Files[0].Output), string(source))
+		assert.Contains(t, logs.String(), "msg=\"wrote file\" path="+statusFile)
+
+		result, err = Run(context.Background(), Options{Path: tmpDir})
+		require.NoError(t, err)
+		assert.Empty(t, result.Changed())
+	})
+}
+
+func TestRun_Files(t *testing.T) {
+	tmpDir := t.TempDir()
+	statusFile := filepath.Join(tmpDir, "status.go")
+	colorFile := filepath.Join(tmpDir, "color.go")
+	require.NoError(t, os.WriteFile(statusFile, []byte(runTestSource), 0644))
+	require.NoError(t, os.WriteFile(colorFile, []byte(`package example
+
+type Color int //#enum
+
+const ColorRed Color = 1
+`), 0644))
+
+	// Only the files
+	result, err := Run(context.Background(), Options{Files: []string{colorFile}, DryRun: true})
+	require.NoError(t, err)
+	require.Len(t, result.Files, 1)
+	assert.Equal(t, colorFile, result.Files[0].Path)
+
+	// Files restrict the Path
+	result, err = Run(context.Background(), Options{Path: tmpDir, Files: []string{statusFile}, DryRun: true})
+	require.NoError(t, err)
+	require.Len(t, result.Files, 1)
+	assert.Equal(t, statusFile, result.Files[0].Path)
+}
+
+func TestRun_Canceled(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "status.go"), []byte(runTestSource), 0644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, err := Run(ctx, Options{Path: tmpDir})
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, result)
+
+	source, err := os.ReadFile(filepath.Join(tmpDir, "status.go"))
+	require.NoError(t, err)
+	assert.Equal(t, runTestSource, string(source))
+}
+
+func TestRewriteFileSource(t *testing.T) {
+	tmpDir := t.TempDir()
+	pkgDir := filepath.Join(tmpDir, "models")
+	require.NoError(t, os.MkdirAll(pkgDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module example.com/test\n"), 0644))
+	filePath := filepath.Join(pkgDir, "status.go")
+
+	// The file doesn't have to exist on disk
+	rewritten, result, err := RewriteFileSource(context.Background(), filePath, []byte(runTestSource), Options{})
+	require.NoError(t, err)
+	assert.Contains(t, string(rewritten), "func (s Status) Valid() bool {")
+	require.Len(t, result.Files, 1)
+	assert.Equal(t, filePath, result.Files[0].Path)
+	assert.NoFileExists(t, filePath)
+
+	// Up to date source is returned as is
+	again, _, err := RewriteFileSource(context.Background(), filePath, rewritten, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, string(rewritten), string(again))
+
+	_, result, err = RewriteFileSource(context.Background(), filePath, []byte(runTestSource), Options{Validate: true})
+	require.Error(t, err)
+	require.Len(t, result.Findings, 1)
+	assert.Equal(t, filePath, result.Findings[0].Pos.Filename)
+
+	t.Run("Config", func(t *testing.T) {
+		config := "layout: file\npackages:\n  models:\n    options: [description]\n"
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ConfigFileName), []byte(config), 0644))
+		generatedFile := filepath.Join(pkgDir, "status"+GeneratedFileSuffix)
+
+		rewritten, _, err := RewriteFileSource(context.Background(), filePath, []byte(runTestSource), Options{DryRun: true})
+		require.NoError(t, err)
+		assert.Equal(t, runTestSource, string(rewritten))
+		assert.NoFileExists(t, generatedFile)
+
+		// The generated file is returned and not written
+		_, result, err := RewriteFileSource(context.Background(), filePath, []byte(runTestSource), Options{})
+		require.NoError(t, err)
+		assert.NoFileExists(t, generatedFile)
+		require.Len(t, result.Changed(), 1)
+		assert.Equal(t, generatedFile, result.Changed()[0].Path)
+		generated := result.Changed()[0].Output
+		assert.Contains(t, string(generated), "func (s Status) Description() string {")
+
+		_, result, err = RewriteFileSource(context.Background(), filePath, []byte(runTestSource), Options{Validate: true})
+		require.Error(t, err)
+		require.Len(t, result.Findings, 1)
+		assert.Equal(t, generatedFile, result.Findings[0].Pos.Filename)
+
+		// An up to date generated file is compared
+		require.NoError(t, os.WriteFile(generatedFile, generated, 0644))
+		_, _, err = RewriteFileSource(context.Background(), filePath, []byte(runTestSource), Options{Validate: true})
+		require.NoError(t, err)
+	})
+}
+
+func TestRun_SkippedDirs(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"go.mod":                  "module example.com/test\n",
+		"models/status.go":        runTestSource,
+		"models/testdata/x.go":    runTestSource,
+		"vendor/example.com/x.go": runTestSource,
+		"_old/x.go":               runTestSource,
+		"nested/go.mod":           "module example.com/nested\n",
+		"nested/x.go":             runTestSource,
+	}
+	for name, content := range files {
+		filePath := filepath.Join(tmpDir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+		require.NoError(t, os.WriteFile(filePath, []byte(content), 0644))
+	}
+
+	result, err := Run(context.Background(), Options{Path: filepath.Join(tmpDir, "..."), DryRun: true})
+	require.NoError(t, err)
+	require.Len(t, result.Files, 1)
+	assert.Equal(t, filepath.Join(tmpDir, "models", "status.go"), result.Files[0].Path)
+
+	// Load walks the same directories
+	enums, err := Load(filepath.Join(tmpDir, "..."))
+	require.NoError(t, err)
+	require.Len(t, enums, 1)
+	assert.Equal(t, filepath.Join(tmpDir, "models"), enums[0].Dir)
+	assert.Equal(t, "example.com/test/models", enums[0].ImportPath)
+}
@@ -0,0 +1,214 @@
+package enums
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Snapshot is a stable record of the enums of a code base
+// used as baseline for compatibility checks, see CompareSnapshots.
+type Snapshot struct {
+	// Enums sorted by Name
+	Enums []SnapshotEnum `json:"enums"`
+}
+
+// SnapshotEnum is an enum type of a Snapshot.
+type SnapshotEnum struct {
+	// Name is the type name qualified by the package import path,
+	// like example.com/project/models.Status, or by the package name
+	// for enums without ImportPath
+	Name string `json:"name"`
+	// Underlying is the underlying type
+	Underlying string `json:"underlying"`
+	// Null is the name of the null constant, empty if not nullable
+	Null string `json:"null,omitempty"`
+	// Values are the enum constants in declaration order
+	Values []SnapshotValue `json:"values"`
+}
+
+// SnapshotValue is an enum constant of a SnapshotEnum.
+type SnapshotValue struct {
+	// Name is the name of the constant
+	Name string `json:"name"`
+	// Literal is the JSON representation of the
+	// string or integer value of the constant
+	Literal json.RawMessage `json:"literal"`
+}
+
+// NewSnapshot returns a Snapshot of the passed enums.
+func NewSnapshot(enums []*Enum) (*Snapshot, error) {
+	snapshot := &Snapshot{Enums: make([]SnapshotEnum, len(enums))}
+	for i, enum := range enums {
+		values, err := enum.LiteralValues()
+		if err != nil {
+			return nil, err
+		}
+		snapshotEnum := SnapshotEnum{
+			Name:       cmp.Or(enum.ImportPath, enum.Package) + "." + enum.Type,
+			Underlying: enum.Underlying,
+			Null:       enum.Null,
+			Values:     make([]SnapshotValue, len(enum.Enums)),
+		}
+		for j, name := range enum.Enums {
+			snapshotEnum.Values[j] = SnapshotValue{Name: name, Literal: json.RawMessage(jsonLiteral(values[j]))}
+		}
+		snapshot.Enums[i] = snapshotEnum
+	}
+	slices.SortFunc(snapshot.Enums, func(a, b SnapshotEnum) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	for i := 1; i < len(snapshot.Enums); i++ {
+		if snapshot.Enums[i].Name == snapshot.Enums[i-1].Name {
+			return nil, fmt.Errorf("enum %s is defined more than once", snapshot.Enums[i].Name)
+		}
+	}
+	return snapshot, nil
+}
+
+// LoadSnapshot reads a Snapshot from a JSON file.
+func LoadSnapshot(filePath string) (*Snapshot, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	var snapshot Snapshot
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("invalid snapshot file %s: %w", filePath, err)
+	}
+	return &snapshot, nil
+}
+
+// JSON returns the indented JSON encoding of the snapshot.
+func (s *Snapshot) JSON() ([]byte, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(s); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// WriteSnapshot writes a Snapshot of the enums found at path
+// as JSON to outFile, see NewSnapshot.
+//
+// Parameters:
+//   - path: Directory or file path to process, may end with "..." to recurse
+//   - outFile: File for the snapshot
+//   - verboseOut: Writer for verbose progress output (nil to disable)
+//   - resultOut: Writer for snapshot output (nil to write to outFile)
+//   - validate: If true, only check that outFile is up to date, an outdated file is returned as Result.Findings
+func WriteSnapshot(path, outFile string, verboseOut, resultOut io.Writer, validate bool) (*Result, error) {
+	enums, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := NewSnapshot(enums)
+	if err != nil {
+		return nil, err
+	}
+	data, err := snapshot.JSON()
+	if err != nil {
+		return nil, err
+	}
+	return writeArtifacts(map[string][]byte{outFile: data}, verboseOut, resultOut, validate)
+}
+
+// LockFileName is the name of the file in the module root with
+// the Snapshot of all enums of the module written by WriteLockFile.
+const LockFileName = "enums.lock.json"
+
+// LockFile returns the path of the LockFileName file
+// in the module root of path, that is the first parent
+// directory with a go.mod file.
+func LockFile(path string) (string, error) {
+	dir, err := filepath.Abs(strings.TrimSuffix(path, "..."))
+	if err != nil {
+		return "", err
+	}
+	if info, err := os.Stat(dir); err == nil && !info.IsDir() {
+		dir = filepath.Dir(dir)
+	}
+	root := moduleRoot(dir)
+	if root == "" {
+		return "", fmt.Errorf("no go.mod found for %s", path)
+	}
+	return filepath.Join(root, LockFileName), nil
+}
+
+// WriteLockFile writes the Snapshot of all enums of the module
+// of path to the LockFileName file in the module root.
+// The lock file makes every change of enum values a reviewable diff,
+// ValidateLockFile checks that it is up to date.
+//
+// Parameters:
+//   - path: Directory or file path in the module
+//   - verboseOut: Writer for verbose progress output (nil to disable)
+//   - resultOut: Writer for the snapshot output (nil to write the lock file)
+//   - validate: If true, only check that the lock file is up to date, see ValidateLockFile (returns no Result)
+func WriteLockFile(path string, verboseOut, resultOut io.Writer, validate bool) (*Result, error) {
+	lockFile, err := LockFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if validate {
+		return nil, validateLockFile(lockFile)
+	}
+	return WriteSnapshot(filepath.Join(filepath.Dir(lockFile), "..."), lockFile, verboseOut, resultOut, false)
+}
+
+// ValidateLockFile returns an error listing the differences
+// if the LockFileName file of the module of path doesn't match
+// the enums of the module. Without a lock file nil is returned.
+func ValidateLockFile(path string) error {
+	lockFile, err := LockFile(path)
+	if err != nil {
+		// Not in a module, so there is no lock file
+		return nil
+	}
+	if _, err := os.Stat(lockFile); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return validateLockFile(lockFile)
+}
+
+func validateLockFile(lockFile string) error {
+	enums, err := Load(filepath.Join(filepath.Dir(lockFile), "..."))
+	if err != nil {
+		return err
+	}
+	current, err := NewSnapshot(enums)
+	if err != nil {
+		return err
+	}
+	data, err := current.JSON()
+	if err != nil {
+		return err
+	}
+	existing, err := os.ReadFile(lockFile)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(existing, data) {
+		return nil
+	}
+	msg := fmt.Sprintf("%s doesn't match the enums of the module, regenerate it with go-enum snapshot", lockFile)
+	if locked, err := LoadSnapshot(lockFile); err == nil {
+		for _, change := range CompareSnapshots(locked, current) {
+			msg += "\n" + change.String()
+		}
+	}
+	return errors.New(msg)
+}
@@ -0,0 +1,102 @@
+package enums
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteSnapshot(t *testing.T) {
+	tmpDir := writeCompatTestModule(t, compatTestSource)
+	snapshotFile := filepath.Join(tmpDir, "snapshot.json")
+
+	_, err := WriteSnapshot(tmpDir+"/...", snapshotFile, nil, nil, false)
+	require.NoError(t, err)
+	data, err := os.ReadFile(snapshotFile)
+	require.NoError(t, err)
+	assert.Equal(t, `{
+  "enums": [
+    {
+      "name": "example.com/test/models.Priority",
+      "underlying": "int",
+      "values": [
+        {
+          "name": "PriorityLow",
+          "literal": 1
+        },
+        {
+          "name": "PriorityHigh",
+          "literal": 2
+        }
+      ]
+    },
+    {
+      "name": "example.com/test/models.Status",
+      "underlying": "string",
+      "null": "StatusNull",
+      "values": [
+        {
+          "name": "StatusNull",
+          "literal": ""
+        },
+        {
+          "name": "StatusActive",
+          "literal": "active"
+        },
+        {
+          "name": "StatusPending",
+          "literal": "pending"
+        }
+      ]
+    }
+  ]
+}
+`, string(data))
+
+	snapshot, err := LoadSnapshot(snapshotFile)
+	require.NoError(t, err)
+	assert.Empty(t, CompareSnapshots(snapshot, snapshot))
+}
+
+func TestWriteLockFile(t *testing.T) {
+	tmpDir := writeCompatTestModule(t, compatTestSource)
+	modelsDir := filepath.Join(tmpDir, "models")
+	lockFile := filepath.Join(tmpDir, LockFileName)
+
+	// Without lock file there is nothing to validate
+	require.NoError(t, ValidateLockFile(modelsDir))
+
+	// The lock file is written to the module root for all packages
+	_, err := WriteLockFile(modelsDir, nil, nil, false)
+	require.NoError(t, err)
+	snapshot, err := LoadSnapshot(lockFile)
+	require.NoError(t, err)
+	require.Len(t, snapshot.Enums, 2)
+	require.NoError(t, ValidateLockFile(modelsDir))
+	_, err = WriteLockFile(tmpDir, nil, nil, true)
+	require.NoError(t, err)
+
+	source := strings.Replace(compatTestSource, `"pending"`, `"waiting"`, 1)
+	require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "models.go"), []byte(source), 0644))
+	err = ValidateLockFile(modelsDir)
+	require.EqualError(t, err, lockFile+" doesn't match the enums of the module, regenerate it with go-enum snapshot\n"+
+		`breaking: example.com/test/models.Status.StatusPending: literal changed from "pending" to "waiting"`)
+	_, err = WriteLockFile(tmpDir, nil, nil, true)
+	require.Error(t, err)
+
+	// Validating the enum methods also validates the lock file
+	err = Rewrite(tmpDir+"/...", Options{Validate: true})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), LockFileName+" doesn't match the enums of the module")
+
+	_, err = WriteLockFile(modelsDir, nil, nil, false)
+	require.NoError(t, err)
+	require.NoError(t, ValidateLockFile(modelsDir))
+
+	_, err = LockFile(t.TempDir())
+	assert.ErrorContains(t, err, "no go.mod found for")
+}
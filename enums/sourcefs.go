@@ -0,0 +1,196 @@
+package enums
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ungerik/go-astvisit"
+)
+
+// sourceFS reads Go source files from the local file system
+// with OS specific paths, or from an fs.FS with slash separated paths.
+type sourceFS struct {
+	fsys fs.FS // nil for the local file system
+}
+
+func (s sourceFS) readFile(name string) ([]byte, error) {
+	if s.fsys == nil {
+		return os.ReadFile(name)
+	}
+	return fs.ReadFile(s.fsys, name)
+}
+
+func (s sourceFS) readDir(name string) ([]fs.DirEntry, error) {
+	if s.fsys == nil {
+		return os.ReadDir(name)
+	}
+	return fs.ReadDir(s.fsys, name)
+}
+
+func (s sourceFS) stat(name string) (fs.FileInfo, error) {
+	if s.fsys == nil {
+		return os.Stat(name)
+	}
+	return fs.Stat(s.fsys, name)
+}
+
+func (s sourceFS) join(elem ...string) string {
+	if s.fsys == nil {
+		return filepath.Join(elem...)
+	}
+	return path.Join(elem...)
+}
+
+func (s sourceFS) dir(name string) string {
+	if s.fsys == nil {
+		return filepath.Dir(name)
+	}
+	return path.Dir(name)
+}
+
+// abs returns the absolute path for the local file system
+// or the cleaned path relative to the root of the fs.FS.
+func (s sourceFS) abs(name string) (string, error) {
+	if s.fsys == nil {
+		return filepath.Abs(name)
+	}
+	name = path.Clean(strings.TrimPrefix(name, "/"))
+	if !fs.ValidPath(name) {
+		return "", fmt.Errorf("invalid path %q", name)
+	}
+	return name, nil
+}
+
+// walkFunc is called by walkFiles for every source file.
+type walkFunc func(fset *token.FileSet, pkg *ast.Package, astFile *ast.File, filePath string) error
+
+// walkFiles calls fn for the Go source file at p or the files of the package
+// in the directory p, sorted by file path. Like for Rewrite, a path ending
+// with "..." also walks all sub-directories, and test files,
+// main packages, and the directories of skipDir are skipped.
+func (s sourceFS) walkFiles(p string, fn walkFunc) error {
+	recursive := strings.HasSuffix(p, "...")
+	if recursive {
+		p = strings.TrimSuffix(p, "...")
+	}
+	p, err := s.abs(p)
+	if err != nil {
+		return err
+	}
+	info, err := s.stat(p)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		fset := token.NewFileSet()
+		pkg, err := s.parsePackage(fset, s.dir(p))
+		if err != nil {
+			return err
+		}
+		astFile, ok := pkg.Files[p]
+		if !ok {
+			return fmt.Errorf("package %s has no file %s", pkg.Name, p)
+		}
+		return fn(fset, pkg, astFile, p)
+	}
+	return s.walkDir(p, recursive, fn)
+}
+
+func (s sourceFS) walkDir(dir string, recursive bool, fn walkFunc) error {
+	fset := token.NewFileSet()
+	pkg, err := s.parsePackage(fset, dir)
+	switch {
+	case err == nil:
+		for _, filePath := range slices.Sorted(maps.Keys(pkg.Files)) {
+			if err := fn(fset, pkg, pkg.Files[filePath], filePath); err != nil {
+				return err
+			}
+		}
+	case !recursive || !errors.Is(err, astvisit.ErrPackageNotFound):
+		return err
+	}
+	if !recursive {
+		return nil
+	}
+	entries, err := s.readDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		subDir := s.join(dir, entry.Name())
+		if !entry.IsDir() || s.skipDir(subDir) {
+			continue
+		}
+		if err := s.walkDir(subDir, true, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skipDir returns true if the sub-directory dir of a walked path
+// is not walked like by the go tool: hidden directories and ones
+// starting with an underscore, testdata, vendor, node_modules,
+// and the root directories of nested modules.
+func (s sourceFS) skipDir(dir string) bool {
+	switch name := path.Base(filepath.ToSlash(dir)); {
+	case name[0] == '.' || name[0] == '_':
+		return true
+	case name == "testdata" || name == "vendor" || name == "node_modules":
+		return true
+	}
+	_, err := s.stat(s.join(dir, "go.mod"))
+	return err == nil
+}
+
+// parsePackage parses the non-test Go files of the package in dir
+// like astvisit.ParsePackage, ignoring a main package.
+func (s sourceFS) parsePackage(fset *token.FileSet, dir string) (*ast.Package, error) {
+	entries, err := s.readDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	pkgs := make(map[string]*ast.Package)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		filePath := s.join(dir, name)
+		src, err := s.readFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+		astFile, err := parser.ParseFile(fset, filePath, src, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		pkg, ok := pkgs[astFile.Name.Name]
+		if !ok {
+			pkg = &ast.Package{Name: astFile.Name.Name, Files: make(map[string]*ast.File)}
+			pkgs[pkg.Name] = pkg
+		}
+		pkg.Files[filePath] = astFile
+	}
+	delete(pkgs, "main") // ignore main package
+	switch len(pkgs) {
+	case 0:
+		return nil, fmt.Errorf("%w in %s", astvisit.ErrPackageNotFound, dir)
+	case 1:
+		for _, pkg := range pkgs {
+			return pkg, nil
+		}
+	}
+	pkgNames := slices.Sorted(maps.Keys(pkgs))
+	return nil, fmt.Errorf("%d packages found in %s: %s", len(pkgs), dir, strings.Join(pkgNames, ", "))
+}
@@ -0,0 +1,322 @@
+package enums
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// SQL dialects of the DDL written by WriteSQL.
+const (
+	// PostgresDialect declares string enums as ENUM types
+	// and integer enums as domains with a CHECK constraint
+	PostgresDialect = "postgres"
+	// MySQLDialect writes the CHECK constraints of enum columns
+	// as comments to be copied into CREATE TABLE statements
+	MySQLDialect = "mysql"
+	// SQLiteDialect writes the CHECK constraints of enum columns
+	// as comments to be copied into CREATE TABLE statements
+	SQLiteDialect = "sqlite"
+)
+
+const sqlHeader = "-- Code generated by go-enum. DO NOT EDIT.\n"
+
+// SQLName returns the snake case name of the enum type
+// used for SQL types, columns, and constraints,
+// like order_status for the type OrderStatus.
+func (e *Enum) SQLName() string {
+	var b strings.Builder
+	runes := []rune(e.Type)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			// Start a new word before an upper case letter following
+			// a lower case letter or digit, or before the last upper case
+			// letter of an abbreviation followed by a lower case letter
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// sqlValues returns the SQL literals of the enum values
+// without the null value, which is represented by SQL NULL.
+func (e *Enum) sqlValues() ([]string, error) {
+	values, err := e.LiteralValues()
+	if err != nil {
+		return nil, err
+	}
+	var literals []string
+	for i, value := range values {
+		if e.Enums[i] == e.Null {
+			continue
+		}
+		switch v := value.(type) {
+		case string:
+			literals = append(literals, "'"+strings.ReplaceAll(v, "'", "''")+"'")
+		default:
+			literals = append(literals, fmt.Sprint(v))
+		}
+	}
+	if len(literals) == 0 {
+		return nil, fmt.Errorf("enum %s.%s has no non-null values to declare in SQL", e.Package, e.Type)
+	}
+	return literals, nil
+}
+
+// quoteSQLName returns the quoted identifier name for the dialect,
+// so that names like order or user don't collide with keywords.
+func quoteSQLName(name, dialect string) string {
+	if dialect == MySQLDialect {
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// sqlIntType returns the Postgres integer type
+// for the underlying type of an integer enum.
+func (e *Enum) sqlIntType() string {
+	switch e.Underlying {
+	case "int8", "uint8", "byte", "int16":
+		return "smallint"
+	case "int32", "uint16":
+		return "integer"
+	default:
+		return "bigint"
+	}
+}
+
+// PGXCodec returns the github.com/jackc/pgx/v5/pgtype codec
+// of the Postgres type of the enum declared by SQLSchema,
+// EnumCodec for string enums and an integer codec
+// for the domain of an integer enum.
+func (e *Enum) PGXCodec() string {
+	if e.IsStringType() {
+		return "&pgtype.EnumCodec{}"
+	}
+	switch e.sqlIntType() {
+	case "smallint":
+		return "pgtype.Int2Codec{}"
+	case "integer":
+		return "pgtype.Int4Codec{}"
+	default:
+		return "pgtype.Int8Codec{}"
+	}
+}
+
+// writeSQLDefinition writes the DDL of the enum for the dialect.
+func (e *Enum) writeSQLDefinition(b *bytes.Buffer, dialect string) error {
+	values, err := e.sqlValues()
+	if err != nil {
+		return err
+	}
+	name := quoteSQLName(e.SQLName(), dialect)
+	check := quoteSQLName(e.SQLName()+"_check", dialect)
+	fmt.Fprintf(b, "\n-- %s.%s\n", e.Package, e.Type)
+	switch {
+	case dialect != PostgresDialect:
+		fmt.Fprintf(b, "-- CONSTRAINT %s CHECK (%s IN (%s))\n", check, name, strings.Join(values, ", "))
+	case e.IsStringType():
+		fmt.Fprintf(b, "CREATE TYPE %s AS ENUM (%s);\n", name, strings.Join(values, ", "))
+	default:
+		fmt.Fprintf(b, "CREATE DOMAIN %s AS %s CONSTRAINT %s CHECK (VALUE IN (%s));\n", name, e.sqlIntType(), check, strings.Join(values, ", "))
+	}
+	return nil
+}
+
+// checkSQL returns an error for an unknown dialect
+// or enums that would have the same SQL name.
+func checkSQL(enums []*Enum, dialect string) error {
+	switch dialect {
+	case PostgresDialect, MySQLDialect, SQLiteDialect:
+	default:
+		return fmt.Errorf("unknown SQL dialect %q, expected %q, %q or %q", dialect, PostgresDialect, MySQLDialect, SQLiteDialect)
+	}
+	names := make(map[string]bool, len(enums))
+	for _, enum := range enums {
+		if !enum.IsStringType() && !enum.IsIntType() {
+			return fmt.Errorf("enum %s.%s with underlying type %s can't be declared in SQL", enum.Package, enum.Type, enum.Underlying)
+		}
+		if names[enum.SQLName()] {
+			return fmt.Errorf("SQL name %s of enum type %s is used by more than one enum", enum.SQLName(), enum.Type)
+		}
+		names[enum.SQLName()] = true
+	}
+	return nil
+}
+
+// SQLSchema returns DDL declaring the passed enums for the SQL dialect,
+// either PostgresDialect, MySQLDialect, or SQLiteDialect.
+//
+// For Postgres a string enum becomes a CREATE TYPE ... AS ENUM statement
+// and an integer enum a CREATE DOMAIN with a CHECK constraint.
+// For the other dialects, which don't have named enum types,
+// a CHECK constraint is written as comment to be copied into the
+// CREATE TABLE statements, using the SQLName as column name.
+// The values are derived from the Literals of the enums,
+// the null value of a nullable enum is represented by SQL NULL.
+// Identifiers are quoted for the dialect and an enum without
+// non-null values results in an error.
+func SQLSchema(enums []*Enum, dialect string) ([]byte, error) {
+	if err := checkSQL(enums, dialect); err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	b.WriteString(sqlHeader)
+	for _, enum := range enums {
+		if err := enum.writeSQLDefinition(&b, dialect); err != nil {
+			return nil, err
+		}
+	}
+	return b.Bytes(), nil
+}
+
+// SQLMigration returns Postgres DDL migrating the enums
+// from the base version to the current version.
+//
+// Values added to a string enum are added with ALTER TYPE ... ADD VALUE
+// at their position, changed integer enums get their domain constraint
+// replaced, and new enums are created. Postgres can't remove values
+// from an ENUM type or change the type of a domain, so removed values
+// and enums, and integer enums with an underlying type mapped to another
+// Postgres integer type are only reported as comments.
+// Enums are matched by their SQLName.
+// Only PostgresDialect is supported, because the other dialects
+// don't have named types that can be altered.
+func SQLMigration(base, current []*Enum, dialect string) ([]byte, error) {
+	if err := checkSQL(base, dialect); err != nil {
+		return nil, err
+	}
+	if err := checkSQL(current, dialect); err != nil {
+		return nil, err
+	}
+	if dialect != PostgresDialect {
+		return nil, errors.New("SQL migrations are only supported for the " + PostgresDialect + " dialect")
+	}
+	oldEnums := make(map[string]*Enum, len(base))
+	for _, enum := range base {
+		oldEnums[enum.SQLName()] = enum
+	}
+
+	var b bytes.Buffer
+	b.WriteString(sqlHeader)
+	for _, enum := range current {
+		name := enum.SQLName()
+		oldEnum, ok := oldEnums[name]
+		delete(oldEnums, name)
+		if !ok {
+			if err := enum.writeSQLDefinition(&b, dialect); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		values, err := enum.sqlValues()
+		if err != nil {
+			return nil, err
+		}
+		oldValues, err := oldEnum.sqlValues()
+		if err != nil {
+			return nil, err
+		}
+		sameIntType := enum.IsStringType() || enum.sqlIntType() == oldEnum.sqlIntType()
+		if slices.Equal(values, oldValues) && sameIntType {
+			continue
+		}
+		ident := quoteSQLName(name, dialect)
+		check := quoteSQLName(name+"_check", dialect)
+		fmt.Fprintf(&b, "\n-- %s.%s\n", enum.Package, enum.Type)
+		if enum.IsStringType() != oldEnum.IsStringType() {
+			fmt.Fprintf(&b, "-- can't migrate the underlying type of %s from %s to %s\n", ident, oldEnum.Underlying, enum.Underlying)
+			continue
+		}
+		if !enum.IsStringType() {
+			if !slices.Equal(values, oldValues) {
+				fmt.Fprintf(&b, "ALTER DOMAIN %s DROP CONSTRAINT %s;\n", ident, check)
+				fmt.Fprintf(&b, "ALTER DOMAIN %s ADD CONSTRAINT %s CHECK (VALUE IN (%s));\n", ident, check, strings.Join(values, ", "))
+			}
+			if !sameIntType {
+				fmt.Fprintf(&b, "-- the underlying type of %s changed from %s to %s, Postgres can't change the type %s of the domain,\n", enum.Type, oldEnum.Underlying, enum.Underlying, oldEnum.sqlIntType())
+				fmt.Fprintf(&b, "-- recreate it after changing the columns using it:\n")
+				fmt.Fprintf(&b, "-- DROP DOMAIN %s;\n", ident)
+				fmt.Fprintf(&b, "-- CREATE DOMAIN %s AS %s CONSTRAINT %s CHECK (VALUE IN (%s));\n", ident, enum.sqlIntType(), check, strings.Join(values, ", "))
+			}
+			continue
+		}
+		for i, value := range values {
+			if slices.Contains(oldValues, value) {
+				continue
+			}
+			switch {
+			case i > 0:
+				fmt.Fprintf(&b, "ALTER TYPE %s ADD VALUE IF NOT EXISTS %s AFTER %s;\n", ident, value, values[i-1])
+			case len(values) > 1:
+				fmt.Fprintf(&b, "ALTER TYPE %s ADD VALUE IF NOT EXISTS %s BEFORE %s;\n", ident, value, values[1])
+			default:
+				fmt.Fprintf(&b, "ALTER TYPE %s ADD VALUE IF NOT EXISTS %s;\n", ident, value)
+			}
+		}
+		reordered := true
+		for _, value := range oldValues {
+			if !slices.Contains(values, value) {
+				fmt.Fprintf(&b, "-- value %s was removed from the Go enum, Postgres can't remove it from the type %s\n", value, ident)
+				reordered = false
+			}
+		}
+		if reordered && len(values) == len(oldValues) {
+			fmt.Fprintf(&b, "-- the order of the values changed, Postgres can't reorder the values of the type %s\n", ident)
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(oldEnums)) {
+		kind := "TYPE"
+		if !oldEnums[name].IsStringType() {
+			kind = "DOMAIN"
+		}
+		fmt.Fprintf(&b, "\n-- %s.%s was removed, drop the %s if it's not used anymore:\n-- DROP %s %s;\n", oldEnums[name].Package, oldEnums[name].Type, strings.ToLower(kind), kind, quoteSQLName(name, dialect))
+	}
+	return b.Bytes(), nil
+}
+
+// WriteSQL writes DDL for the enums found at path, see SQLSchema.
+// If baseRef is not empty, a migration from the enums at the git reference
+// baseRef to the current enums is written instead, see SQLMigration.
+//
+// Parameters:
+//   - path: Directory or file path to process, may end with "..." to recurse
+//   - outFile: File for the generated DDL
+//   - dialect: PostgresDialect, MySQLDialect, or SQLiteDialect
+//   - baseRef: Git reference of the old version for a migration (empty for the schema)
+//   - verboseOut: Writer for verbose progress output (nil to disable)
+//   - resultOut: Writer for generated DDL output (nil to write to outFile)
+//   - validate: If true, only check that outFile is up to date, an outdated file is returned as Result.Findings
+func WriteSQL(path, outFile, dialect, baseRef string, verboseOut, resultOut io.Writer, validate bool) (*Result, error) {
+	if outFile == "" && (resultOut == nil || validate) {
+		return nil, errors.New("no output file for SQL")
+	}
+	enums, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	var ddl []byte
+	if baseRef == "" {
+		ddl, err = SQLSchema(enums, dialect)
+	} else {
+		var baseEnums []*Enum
+		baseEnums, err = LoadGitRef(baseRef, path)
+		if err != nil {
+			return nil, err
+		}
+		ddl, err = SQLMigration(baseEnums, enums, dialect)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return writeArtifacts(map[string][]byte{outFile: ddl}, verboseOut, resultOut, validate)
+}
@@ -0,0 +1,253 @@
+package enums
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sqlTestSource = `package models
+
+type OrderStatus string //#enum
+
+const (
+	OrderStatusNull    OrderStatus = "" //#null
+	OrderStatusPending OrderStatus = "pending"
+	OrderStatusShipped OrderStatus = "shipped"
+	OrderStatusCustom  OrderStatus = "customer's"
+)
+
+type Priority int16 //#enum
+
+const (
+	PriorityLow  Priority = 1
+	PriorityHigh Priority = 2
+)
+`
+
+func findSQLTestEnums(t *testing.T, source string) []*Enum {
+	t.Helper()
+	fset, pkg, astFile := parseSource(t, source)
+	enums, err := Find(fset, pkg, astFile)
+	require.NoError(t, err)
+	return []*Enum{enums["OrderStatus"], enums["Priority"]}
+}
+
+func TestEnum_SQLName(t *testing.T) {
+	for typ, expected := range map[string]string{
+		"Status":      "status",
+		"OrderStatus": "order_status",
+		"HTTPMethod":  "http_method",
+		"UserID":      "user_id",
+		"V2Type":      "v2_type",
+	} {
+		assert.Equal(t, expected, (&Enum{Type: typ}).SQLName(), typ)
+	}
+}
+
+func TestSQLSchema(t *testing.T) {
+	enums := findSQLTestEnums(t, sqlTestSource)
+
+	ddl, err := SQLSchema(enums, PostgresDialect)
+	require.NoError(t, err)
+	assert.Equal(t, `-- Code generated by go-enum. DO NOT EDIT.
+
+-- models.OrderStatus
+CREATE TYPE "order_status" AS ENUM ('pending', 'shipped', 'customer''s');
+
+-- models.Priority
+CREATE DOMAIN "priority" AS smallint CONSTRAINT "priority_check" CHECK (VALUE IN (1, 2));
+`, string(ddl))
+
+	for dialect, quote := range map[string]string{MySQLDialect: "`", SQLiteDialect: `"`} {
+		ddl, err = SQLSchema(enums, dialect)
+		require.NoError(t, err)
+		assert.Equal(t, strings.ReplaceAll(`-- Code generated by go-enum. DO NOT EDIT.
+
+-- models.OrderStatus
+-- CONSTRAINT "order_status_check" CHECK ("order_status" IN ('pending', 'shipped', 'customer''s'))
+
+-- models.Priority
+-- CONSTRAINT "priority_check" CHECK ("priority" IN (1, 2))
+`, `"`, quote), string(ddl), dialect)
+	}
+
+	_, err = SQLSchema(enums, "oracle")
+	assert.EqualError(t, err, `unknown SQL dialect "oracle", expected "postgres", "mysql" or "sqlite"`)
+}
+
+func TestSQLSchema_ReservedWord(t *testing.T) {
+	fset, pkg, astFile := parseSource(t, `package models
+
+type Order string //#enum
+
+const (
+	OrderNew  Order = "new"
+	OrderPaid Order = "paid"
+)
+
+type User int //#enum
+
+const (
+	UserGuest User = 1
+	UserAdmin User = 2
+)
+`)
+	found, err := Find(fset, pkg, astFile)
+	require.NoError(t, err)
+	enums := []*Enum{found["Order"], found["User"]}
+
+	ddl, err := SQLSchema(enums, PostgresDialect)
+	require.NoError(t, err)
+	assert.Equal(t, `-- Code generated by go-enum. DO NOT EDIT.
+
+-- models.Order
+CREATE TYPE "order" AS ENUM ('new', 'paid');
+
+-- models.User
+CREATE DOMAIN "user" AS bigint CONSTRAINT "user_check" CHECK (VALUE IN (1, 2));
+`, string(ddl))
+
+	ddl, err = SQLSchema(enums, MySQLDialect)
+	require.NoError(t, err)
+	assert.Contains(t, string(ddl), "-- CONSTRAINT `order_check` CHECK (`order` IN ('new', 'paid'))\n")
+}
+
+func TestSQLSchema_OnlyNull(t *testing.T) {
+	fset, pkg, astFile := parseSource(t, `package models
+
+type Flag int //#enum
+
+const (
+	FlagNone Flag = 0 //#null
+)
+`)
+	found, err := Find(fset, pkg, astFile)
+	require.NoError(t, err)
+
+	for _, dialect := range []string{PostgresDialect, MySQLDialect, SQLiteDialect} {
+		_, err = SQLSchema([]*Enum{found["Flag"]}, dialect)
+		assert.EqualError(t, err, "enum models.Flag has no non-null values to declare in SQL", dialect)
+	}
+	_, err = SQLMigration(nil, []*Enum{found["Flag"]}, PostgresDialect)
+	assert.EqualError(t, err, "enum models.Flag has no non-null values to declare in SQL")
+}
+
+func TestSQLMigration(t *testing.T) {
+	base := findSQLTestEnums(t, sqlTestSource)
+	current := findSQLTestEnums(t, `package models
+
+type OrderStatus string //#enum
+
+const (
+	OrderStatusNew     OrderStatus = "new"
+	OrderStatusPending OrderStatus = "pending"
+	OrderStatusPacked  OrderStatus = "packed"
+	OrderStatusShipped OrderStatus = "shipped"
+)
+
+type Priority int16 //#enum
+
+const (
+	PriorityLow  Priority = 1
+	PriorityHigh Priority = 2
+	PriorityUrgent Priority = 3
+)
+`)
+
+	ddl, err := SQLMigration(base, current, PostgresDialect)
+	require.NoError(t, err)
+	assert.Equal(t, `-- Code generated by go-enum. DO NOT EDIT.
+
+-- models.OrderStatus
+ALTER TYPE "order_status" ADD VALUE IF NOT EXISTS 'new' BEFORE 'pending';
+ALTER TYPE "order_status" ADD VALUE IF NOT EXISTS 'packed' AFTER 'pending';
+-- value 'customer''s' was removed from the Go enum, Postgres can't remove it from the type "order_status"
+
+-- models.Priority
+ALTER DOMAIN "priority" DROP CONSTRAINT "priority_check";
+ALTER DOMAIN "priority" ADD CONSTRAINT "priority_check" CHECK (VALUE IN (1, 2, 3));
+`, string(ddl))
+
+	ddl, err = SQLMigration(base, current[:1], PostgresDialect)
+	require.NoError(t, err)
+	assert.Contains(t, string(ddl), "\n-- models.Priority was removed, drop the domain if it's not used anymore:\n-- DROP DOMAIN \"priority\";\n")
+
+	ddl, err = SQLMigration(nil, current[:1], PostgresDialect)
+	require.NoError(t, err)
+	assert.Contains(t, string(ddl), "CREATE TYPE \"order_status\" AS ENUM ('new', 'pending', 'packed', 'shipped');\n")
+
+	// Changed integer type with the same values
+	current = findSQLTestEnums(t, strings.Replace(sqlTestSource, "type Priority int16", "type Priority int32", 1))
+	ddl, err = SQLMigration(base, current, PostgresDialect)
+	require.NoError(t, err)
+	assert.Equal(t, `-- Code generated by go-enum. DO NOT EDIT.
+
+-- models.Priority
+-- the underlying type of Priority changed from int16 to int32, Postgres can't change the type smallint of the domain,
+-- recreate it after changing the columns using it:
+-- DROP DOMAIN "priority";
+-- CREATE DOMAIN "priority" AS integer CONSTRAINT "priority_check" CHECK (VALUE IN (1, 2));
+`, string(ddl))
+
+	// Same Postgres integer type
+	current = findSQLTestEnums(t, strings.Replace(sqlTestSource, "type Priority int16", "type Priority uint8", 1))
+	ddl, err = SQLMigration(base, current, PostgresDialect)
+	require.NoError(t, err)
+	assert.Equal(t, sqlHeader, string(ddl))
+
+	_, err = SQLMigration(base, current, MySQLDialect)
+	assert.EqualError(t, err, "SQL migrations are only supported for the postgres dialect")
+}
+
+func TestWriteSQL_GitRef(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	tmpDir := t.TempDir()
+	gitCmd := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = tmpDir
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+	modelsDir := filepath.Join(tmpDir, "models")
+	require.NoError(t, os.MkdirAll(modelsDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module example.com/test\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "models.go"), []byte(sqlTestSource), 0644))
+	gitCmd("init", "-q")
+	gitCmd("add", "-A")
+	gitCmd("commit", "-q", "-m", "base")
+
+	source := bytes.Replace([]byte(sqlTestSource), []byte("PriorityHigh Priority = 2\n"), []byte("PriorityHigh Priority = 2\n\tPriorityUrgent Priority = 3\n"), 1)
+	require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "models.go"), source, 0644))
+
+	var out bytes.Buffer
+	_, err := WriteSQL(modelsDir, "", PostgresDialect, "HEAD", nil, &out, false)
+	require.NoError(t, err)
+	assert.Equal(t, `-- Code generated by go-enum. DO NOT EDIT.
+
+-- models.Priority
+ALTER DOMAIN "priority" DROP CONSTRAINT "priority_check";
+ALTER DOMAIN "priority" ADD CONSTRAINT "priority_check" CHECK (VALUE IN (1, 2, 3));
+`, out.String())
+
+	// Only the package and go.mod are extracted from the archive
+	paths, err := gitRefPaths("HEAD", tmpDir, "models", false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{":(glob)models/*", "go.mod"}, paths)
+	paths, err = gitRefPaths("HEAD", tmpDir, "models", true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"models", "go.mod"}, paths)
+
+	_, err = WriteSQL(modelsDir, "", PostgresDialect, "no-such-ref", nil, &out, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "git archive:")
+}
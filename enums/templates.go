@@ -1,6 +1,197 @@
 package enums
 
-import "text/template"
+import (
+	"io"
+	"text/template"
+)
+
+// declKind is the kind of declaration generated by a methodTemplate.
+type declKind int
+
+const (
+	methodKind declKind = iota
+	funcKind
+	varKind
+)
+
+// methodTemplate is the template of a single generated method, function,
+// or variable that can be replaced by a hand-written version marked with //#custom.
+type methodTemplate struct {
+	// name of the generated declaration, used to find existing declarations
+	name string
+	tmpl interface {
+		Execute(w io.Writer, data any) error
+	}
+	// imports needed by the generated code
+	imports []string
+	kind    declKind
+}
+
+// methodTemplates returns the templates of all methods
+// generated for the enum in the order they are written.
+func (e *Enum) methodTemplates() []methodTemplate {
+	tmpls := []methodTemplate{
+		{"Valid", validTemplate, nil, methodKind},
+		{"Validate", validateTemplate, []string{`"fmt"`}, methodKind},
+		{"Enums", enumsTemplate, nil, methodKind},
+		{"EnumStrings", enumStringsTemplate, e.EnumStringsImports(), methodKind},
+	}
+	if e.HasDeprecated() {
+		tmpls = append(tmpls,
+			methodTemplate{"AllIncludingDeprecated", allIncludingDeprecatedTemplate, nil, methodKind},
+			methodTemplate{"IsDeprecated", isDeprecatedTemplate, nil, methodKind},
+		)
+	}
+	if e.OnDeprecated {
+		tmpls = append(tmpls, methodTemplate{"OnDeprecated" + e.Type, onDeprecatedTemplate, nil, varKind})
+	}
+	switch {
+	case e.hasOtherString:
+		// Don't collide with a hand-written or stringer generated
+		// String method in another file of the package
+	case e.IsStringType():
+		tmpls = append(tmpls, methodTemplate{"String", stringMethodsTemplate, nil, methodKind})
+	case e.Flag || e.PFlag || e.IntString == IntStringName:
+		// Command line flag values need a String method
+		tmpls = append(tmpls, methodTemplate{"String", intStringTemplate, []string{`"fmt"`}, methodKind})
+	}
+	if e.Default != "" {
+		tmpls = append(tmpls,
+			methodTemplate{"Default" + e.Type, defaultTemplate, nil, funcKind},
+			methodTemplate{"SetDefault", setDefaultTemplate, nil, methodKind},
+		)
+	}
+	if e.Description {
+		tmpls = append(tmpls, methodTemplate{"Description", descriptionTemplate, nil, methodKind})
+	}
+	if e.HasLabels() || e.I18N {
+		tmpls = append(tmpls, methodTemplate{"Label", labelTemplate, []string{`"fmt"`}, methodKind})
+	}
+	if e.I18N {
+		tmpls = append(tmpls, methodTemplate{"LabelFor", labelForTemplate, []string{`"golang.org/x/text/language"`, `"golang.org/x/text/message"`}, methodKind})
+	}
+	if e.IsNullable() {
+		tmpls = append(tmpls,
+			methodTemplate{"IsNull", isNullTemplate, nil, methodKind},
+			methodTemplate{"IsNotNull", isNotNullTemplate, nil, methodKind},
+			methodTemplate{"SetNull", setNullTemplate, nil, methodKind},
+			methodTemplate{"MarshalJSON", nullableMarshalJSONTemplate, []string{`"encoding/json"`}, methodKind},
+		)
+	}
+	if e.Unknown != "" {
+		tmpls = append(tmpls, methodTemplate{"IsUnknown", isUnknownTemplate, nil, methodKind})
+	}
+	switch {
+	case e.HasParser():
+		// Decode all input with the parse function so that
+		// aliases and normalization are applied everywhere
+		var parseImports []string
+		if e.StrictParser() {
+			parseImports = append(parseImports, `"fmt"`)
+		}
+		if len(e.Normalize) > 0 {
+			parseImports = append(parseImports, `"strings"`)
+		}
+		unmarshalJSONImports := []string{`"encoding/json"`}
+		if e.IsNullable() {
+			unmarshalJSONImports = append(unmarshalJSONImports, `"bytes"`)
+		}
+		tmpls = append(tmpls,
+			methodTemplate{"Parse" + e.Type, parseTemplate, parseImports, funcKind},
+			methodTemplate{"UnmarshalText", parseUnmarshalTextTemplate, nil, methodKind},
+			methodTemplate{"UnmarshalJSON", parseUnmarshalJSONTemplate, unmarshalJSONImports, methodKind},
+			methodTemplate{"Scan", parseScanTemplate, []string{`"fmt"`}, methodKind},
+		)
+	case e.IsIntType() && ((e.Unknown != "" && !e.KeepUnknown) || e.CallsOnDeprecated() || (e.Strict && e.Unknown == "")):
+		// Integers are decoded as is with //#unknown:keep, so decoding methods
+		// are only needed to map to the unknown value, call the hook,
+		// or validate strictly
+		unmarshalJSONImports := []string{`"encoding/json"`}
+		if e.IsNullable() {
+			unmarshalJSONImports = append(unmarshalJSONImports, `"bytes"`)
+		}
+		tmpls = append(tmpls,
+			methodTemplate{"UnmarshalJSON", intUnmarshalJSONTemplate, unmarshalJSONImports, methodKind},
+			methodTemplate{"Scan", intScanTemplate, []string{`"fmt"`}, methodKind},
+		)
+	case e.IsNullable():
+		tmpls = append(tmpls, methodTemplate{"UnmarshalJSON", nullableUnmarshalJSONTemplate, []string{`"bytes"`, `"encoding/json"`}, methodKind})
+		switch {
+		case e.IsStringType():
+			tmpls = append(tmpls, methodTemplate{"Scan", nullableStringScanTemplate, []string{`"fmt"`}, methodKind})
+		case e.IsIntType():
+			tmpls = append(tmpls, methodTemplate{"Scan", nullableIntScanTemplate, []string{`"fmt"`}, methodKind})
+		}
+	}
+	if e.IsNullable() {
+		switch {
+		case e.IsStringType():
+			tmpls = append(tmpls, methodTemplate{"Value", nullableStringValueTemplate, []string{`"database/sql/driver"`}, methodKind})
+		case e.IsIntType():
+			tmpls = append(tmpls, methodTemplate{"Value", nullableIntValueTemplate, []string{`"database/sql/driver"`}, methodKind})
+		}
+	}
+	if e.PGX {
+		pgtypeImports := []string{`"github.com/jackc/pgx/v5/pgtype"`}
+		switch {
+		case e.IsStringType():
+			tmpls = append(tmpls,
+				methodTemplate{"ScanText", pgxScanTextTemplate, append(pgtypeImports, `"fmt"`), methodKind},
+				methodTemplate{"TextValue", pgxTextValueTemplate, pgtypeImports, methodKind},
+			)
+		case e.IsIntType():
+			tmpls = append(tmpls,
+				methodTemplate{"ScanInt64", pgxScanInt64Template, append(pgtypeImports, `"fmt"`), methodKind},
+				methodTemplate{"Int64Value", pgxInt64ValueTemplate, pgtypeImports, methodKind},
+			)
+		}
+		tmpls = append(tmpls, methodTemplate{"Register" + e.Type + "PgType", pgxRegisterTemplate, pgtypeImports, funcKind})
+	}
+	if e.Slog {
+		tmpls = append(tmpls, methodTemplate{"LogValue", logValueTemplate, []string{`"log/slog"`}, methodKind})
+	}
+	if e.Flag || e.PFlag {
+		setImports := []string{`"fmt"`, `"strings"`}
+		if !e.IsStringType() {
+			setImports = append(setImports, `"strconv"`)
+		}
+		tmpls = append(tmpls,
+			methodTemplate{"Set", flagSetTemplate, setImports, methodKind},
+			methodTemplate{"Type", flagTypeTemplate, nil, methodKind},
+		)
+	}
+	if e.Flag {
+		tmpls = append(tmpls, methodTemplate{e.Type + "Var", flagVarTemplate, []string{`"flag"`, `"fmt"`, `"strings"`}, funcKind})
+	}
+	if e.PFlag {
+		tmpls = append(tmpls,
+			methodTemplate{e.Type + "VarP", pflagVarPTemplate, []string{`"fmt"`, `"strings"`, `"github.com/spf13/pflag"`}, funcKind},
+			methodTemplate{"Complete" + e.Type, completeTemplate, []string{`"strings"`}, funcKind},
+		)
+	}
+	if e.JSONSchema {
+		tmpls = append(tmpls, methodTemplate{"JSONSchema", jsonSchemaMethodTemplate, []string{`"github.com/invopop/jsonschema"`}, methodKind})
+	}
+	if e.Assert {
+		tmpls = append(tmpls, methodTemplate{"_", assertTemplate, []string{`"github.com/ungerik/go-enum/enum"`}, varKind})
+	}
+	if e.Registry {
+		tmpls = append(tmpls, methodTemplate{"init", registryInitTemplate, []string{`"github.com/ungerik/go-enum/enumreg"`}, funcKind})
+	}
+	// Declarations of user-defined templates, see Options.Templates
+	return append(tmpls, e.userDecls...)
+}
+
+// generates returns true if a declaration
+// with the name and kind is generated for the enum.
+func (e *Enum) generates(name string, kind declKind) bool {
+	for _, t := range e.methodTemplates() {
+		if t.name == name && t.kind == kind {
+			return true
+		}
+	}
+	return false
+}
 
 // Valid + Validate templates, split per method so `//#custom` can target
 // each individually. Generated for all enum types.
@@ -84,31 +275,398 @@ func ({{.Recv}} {{.Type}}) String() string {
 }
 `))
 
+// Default function and method. Generated for enum types
+// with a default value (marked with //#default).
+
+var defaultTemplate = template.Must(template.New("").Parse(`
+// Default{{.Type}} returns the default value {{.Default}} for {{.Type}}
+func Default{{.Type}}() {{.Type}} {
+	return {{.Default}}
+}
+`))
+
+var setDefaultTemplate = template.Must(template.New("").Parse(`
+// SetDefault sets the default value {{.Default}} at {{.Recv}}
+func ({{.Recv}} *{{.Type}}) SetDefault() {
+	*{{.Recv}} = {{.Default}}
+}
+`))
+
+// logValueTemplate provides the LogValue method implementing log/slog.LogValuer
+// that logs the display label of valid values and a group with the raw value
+// and invalid=true for invalid values. Generated for enum types with the ,slog flag.
+var logValueTemplate = template.Must(template.New("").Parse(`
+// LogValue implements the log/slog.LogValuer interface for {{.Type}}
+// by returning the label of {{.Recv}} or a group with the raw value
+// and invalid=true if {{.Recv}} is none of the valid values
+func ({{.Recv}} {{.Type}}) LogValue() slog.Value {
+	switch {{.Recv}} {
+	{{$labels := .DisplayLabels}}{{range $index, $element := .Enums}}case {{$element}}:
+		return slog.StringValue({{index $labels $index | printf "%q"}})
+	{{end}}}
+	return slog.GroupValue(
+		slog.Any("value", {{.Underlying}}({{.Recv}})),
+		slog.Bool("invalid", true),
+	)
+}
+`))
+
+// intStringTemplate provides the String method for integer enum types
+// that need it as command line flag values (,flag or ,pflag flags)
+// or use the IntStringName strategy.
+var intStringTemplate = template.Must(template.New("").Parse(`
+// String implements the fmt.Stringer interface for {{.Type}}{{if eq .IntString "name"}}
+// by returning the constant name of valid values
+// and the number of invalid values{{end}}
+func ({{.Recv}} {{.Type}}) String() string {
+	{{if eq .IntString "name"}}switch {{.Recv}} {
+	{{range .Enums}}case {{.}}:
+		return "{{.}}"
+	{{end}}}
+	{{end}}return fmt.Sprint({{.Underlying}}({{.Recv}}))
+}
+`))
+
+// Command line flag methods and functions. Set and Type implement the
+// flag.Value and github.com/spf13/pflag.Value interfaces together with String.
+// Generated for enum types with the ,flag or ,pflag flags.
+
+var flagSetTemplate = template.Must(template.New("").Parse(`
+// Set implements the flag.Value interface for {{.Type}}
+// by setting {{.Recv}} to the valid value parsed from str
+func ({{.Recv}} *{{.Type}}) Set(str string) error {
+	{{if .HasParser}}value, err := Parse{{.Type}}(str)
+	if err != nil {
+		return err
+	}
+	{{if and .Unknown (not .KeepUnknown)}}// Reject typos that Parse{{.Type}} maps to {{.Unknown}}
+	if value == {{.Unknown}}{{range .UnknownInputs}} && {{$.FlagParseKey}} != {{.}}{{end}} {
+		return fmt.Errorf("invalid value %q for type {{.Package}}.{{.Type}}, expected one of: %s", str, strings.Join(value.EnumStrings(), ", "))
+	}
+	{{end}}{{else if .IsStringType}}value := {{.Type}}(str)
+	{{else}}{{if eq .IntString "name"}}for _, value := range {{.Type}}({{.ZeroValue}}).{{if .HasDeprecated}}AllIncludingDeprecated{{else}}Enums{{end}}() {
+		if value.String() == str {
+			*{{.Recv}} = value
+			return nil
+		}
+	}
+	{{end}}i, err := strconv.{{if .IsUnsignedType}}ParseUint{{else}}ParseInt{{end}}(str, 10, {{.BitSize}})
+	if err != nil {
+		return fmt.Errorf("invalid value %q for type {{.Package}}.{{.Type}}: %w", str, err)
+	}
+	value := {{.Type}}(i)
+	{{end}}if !value.Valid() {
+		return fmt.Errorf("invalid value %q for type {{.Package}}.{{.Type}}, expected one of: %s", str, strings.Join(value.EnumStrings(), ", "))
+	}
+	*{{.Recv}} = value
+	return nil
+}
+`))
+
+var flagTypeTemplate = template.Must(template.New("").Parse(`
+// Type returns the type name {{.Type}} shown in the help
+// of github.com/spf13/pflag flags
+func ({{.Type}}) Type() string {
+	return "{{.Type}}"
+}
+`))
+
+var flagVarTemplate = template.Must(template.New("").Parse(`
+// {{.Type}}Var defines a {{.Type}} flag with the name, default value,
+// and usage string in fs. The argument p points to the variable
+// that stores the value of the flag. The usage is extended
+// with the valid values of {{.Type}}.
+func {{.Type}}Var(fs *flag.FlagSet, p *{{.Type}}, name string, value {{.Type}}, usage string) {
+	*p = value
+	fs.Var(p, name, fmt.Sprintf("%s (one of: %s)", usage, strings.Join(value.EnumStrings(), ", ")))
+}
+`))
+
+var pflagVarPTemplate = template.Must(template.New("").Parse(`
+// {{.Type}}VarP defines a {{.Type}} flag with the name, shorthand letter,
+// default value, and usage string in the github.com/spf13/pflag.FlagSet fs.
+// The argument p points to the variable that stores the value of the flag.
+// The usage is extended with the valid values of {{.Type}},
+// use Complete{{.Type}} for shell completion.
+func {{.Type}}VarP(fs *pflag.FlagSet, p *{{.Type}}, name, shorthand string, value {{.Type}}, usage string) {
+	*p = value
+	fs.VarP(p, name, shorthand, fmt.Sprintf("%s (one of: %s)", usage, strings.Join(value.EnumStrings(), ", ")))
+}
+`))
+
+var completeTemplate = template.Must(template.New("").Parse(`
+// Complete{{.Type}} returns the valid values of {{.Type}} starting with
+// toComplete for shell completion, for example in a function
+// registered with cobra.Command.RegisterFlagCompletionFunc.{{if and .Description .HasDescriptions}}
+// The values are followed by a tab and their description.{{end}}
+func Complete{{.Type}}(toComplete string) []string {
+	var completions []string
+	for _, value := range {{.Type}}({{.ZeroValue}}).Enums() {
+		if str := value.String(); str != "" && strings.HasPrefix(str, toComplete) {
+			{{if and .Description .HasDescriptions}}if description := value.Description(); description != "" {
+				str += "\t" + description
+			}
+			{{end}}completions = append(completions, str)
+		}
+	}
+	return completions
+}
+`))
+
+// descriptionTemplate provides the Description method returning the doc
+// comments of the enum constants. Generated for enum types with the
+// ,description flag.
+var descriptionTemplate = template.Must(template.New("").Parse(`
+// Description returns the doc comment of the {{.Type}} constant {{.Recv}}
+func ({{.Recv}} {{.Type}}) Description() string {
+	switch {{.Recv}} {
+	{{range $index, $element := .Enums}}{{with index $.Descriptions $index}}case {{$element}}:
+		return {{printf "%q" .}}
+	{{end}}{{end}}}
+	return ""
+}
+`))
+
+// labelTemplate provides the Label method returning the //#label of the
+// enum constants. Generated for enum types with labels or the ,i18n flag.
+var labelTemplate = template.Must(template.New("").Parse(`
+// Label returns the human readable label of {{.Recv}}
+func ({{.Recv}} {{.Type}}) Label() string {
+	switch {{.Recv}} {
+	{{$labels := .DisplayLabels}}{{range $index, $element := .Enums}}case {{$element}}:
+		return {{index $labels $index | printf "%q"}}
+	{{end}}}
+	return {{if .IsStringType}}string({{.Recv}}){{else}}fmt.Sprint({{.Underlying}}({{.Recv}})){{end}}
+}
+`))
+
+// labelForTemplate provides the LabelFor method translating the labels
+// with the golang.org/x/text/message catalog using the keys "<Type>.<Const>".
+// Generated for enum types with the ,i18n flag.
+var labelForTemplate = template.Must(template.New("").Parse(`
+// LabelFor returns the label of {{.Recv}} translated to lang
+// using the golang.org/x/text/message catalog key "{{.Type}}.<Const>"
+func ({{.Recv}} {{.Type}}) LabelFor(lang language.Tag) string {
+	switch {{.Recv}} {
+	{{$labels := .MessageLabels}}{{range $index, $element := .Enums}}case {{$element}}:
+		return message.NewPrinter(lang).Sprintf(message.Key("{{$.Type}}.{{$element}}", {{index $labels $index | printf "%q"}}))
+	{{end}}}
+	return {{.Recv}}.Label()
+}
+`))
+
 // Enums + EnumStrings templates, split per method so `//#custom` can
 // target each individually. These methods return all valid enum values
 // as a slice.
 
 var enumsTemplate = template.Must(template.New("").Parse(`
-// Enums returns all valid values for {{.Type}}
+// Enums returns all valid values for {{.Type}}{{if .HasDeprecated}}
+// except the deprecated ones, see AllIncludingDeprecated{{end}}
 func ({{.Type}}) Enums() []{{.Type}} {
 	return []{{.Type}}{
-		{{range .Enums}}{{.}},
+		{{range .CurrentEnums}}{{.}},
 {{end}}
 	}
 }
 `))
 
 var enumStringsTemplate = template.Must(template.New("").Parse(`
-// EnumStrings returns all valid values for {{.Type}} as strings
+// EnumStrings returns all valid values for {{.Type}} as strings{{if eq .IntString "name"}}
+// returned by String, which are the constant names{{end}}{{if .HasDeprecated}}
+// except the deprecated ones{{end}}
 func ({{.Type}}) EnumStrings() []string {
 	return []string{
-		{{if .IsStringType}}{{range .Literals}}{{.}},
-{{end}}{{else}}{{range .Literals}}"{{.}}",
-{{end}}{{end}}
+		{{range .EnumStringLiterals}}{{.}},
+{{end}}
+	}
+}
+`))
+
+// Deprecated value methods and hook variable. Generated for enum types
+// with deprecated values ("Deprecated: " doc paragraph or //#deprecated)
+// and the ,ondeprecated flag.
+
+var allIncludingDeprecatedTemplate = template.Must(template.New("").Parse(`
+// AllIncludingDeprecated returns all valid values for {{.Type}}
+// including the deprecated ones
+func ({{.Type}}) AllIncludingDeprecated() []{{.Type}} {
+	return []{{.Type}}{
+		{{range .Enums}}{{.}},
+{{end}}
+	}
+}
+`))
+
+var isDeprecatedTemplate = template.Must(template.New("").Parse(`
+// IsDeprecated returns true if {{.Recv}} is a deprecated value of {{.Type}}
+func ({{.Recv}} {{.Type}}) IsDeprecated() bool {
+	switch {{.Recv}} {
+	case {{range $index, $element := .DeprecatedEnums}}{{if $index}}, {{end}}{{$element}}{{end}}:
+		return true
 	}
+	return false
 }
 `))
 
+var onDeprecatedTemplate = template.Must(template.New("").Parse(`
+// OnDeprecated{{.Type}} is called with deprecated values decoded
+// by the generated methods of {{.Type}} if not nil,
+// for example to log the use of deprecated values.
+var OnDeprecated{{.Type}} func({{.Type}})
+`))
+
+// Parse function and decoding methods using it. Generated instead of
+// the nullable UnmarshalJSON and Scan for enum types with a parser,
+// see Enum.HasParser.
+
+var parseTemplate = template.Must(template.New("").Parse(`
+// Parse{{.Type}} returns the {{.Type}} value for the string s{{if .StrictParser}}
+// or an error if s is none of the valid values{{end}}.{{if .HasAliases}}
+// The aliases of legacy spellings are accepted and
+// return the canonical value.{{end}}{{with .Normalize}}
+// The string is normalized before parsing with: {{range $index, $mode := .}}{{if $index}}, {{end}}{{$mode}}{{end}}.{{end}}{{if .KeepUnknown}}
+// Unrecognized strings are returned as is without error
+// for forward compatibility, see IsUnknown.{{else if .Unknown}}
+// Unrecognized strings return {{.Unknown}} without error
+// for forward compatibility.{{else if not .Strict}}
+// Unrecognized strings are returned as is without error
+// like the other decoders do, use Validate to check them.{{end}}{{if .CallsOnDeprecated}}
+// OnDeprecated{{.Type}} is called for deprecated values.{{end}}{{if .DefaultOnEmpty}}
+// An empty string returns the default value {{.Default}}.{{end}}
+func Parse{{.Type}}(s string) ({{.Type}}, error) {
+	switch {{.ParseKey}} {
+	{{range .ParseCases}}case {{range $index, $input := .Inputs}}{{if $index}}, {{end}}{{$input}}{{end}}:
+		{{if and $.OnDeprecated .Deprecated}}if OnDeprecated{{$.Type}} != nil {
+			OnDeprecated{{$.Type}}({{.Const}})
+		}
+		{{end}}return {{.Const}}, nil
+	{{end}}}
+	{{if .StrictParser}}return {{.ZeroValue}}, fmt.Errorf("invalid value %q for type {{.Package}}.{{.Type}}", s){{else if and .Unknown (not .KeepUnknown)}}return {{.Unknown}}, nil{{else}}return {{.Type}}(s), nil{{end}}
+}
+`))
+
+var parseUnmarshalTextTemplate = template.Must(template.New("").Parse(`
+// UnmarshalText implements encoding.TextUnmarshaler using Parse{{.Type}}
+func ({{.Recv}} *{{.Type}}) UnmarshalText(text []byte) error {
+	value, err := Parse{{.Type}}(string(text))
+	if err != nil {
+		return err
+	}
+	*{{.Recv}} = value
+	return nil
+}
+`))
+
+var parseUnmarshalJSONTemplate = template.Must(template.New("").Parse(`
+// UnmarshalJSON implements encoding/json.Unmarshaler using Parse{{.Type}}
+func ({{.Recv}} *{{.Type}}) UnmarshalJSON(j []byte) error {
+	{{if .IsNullable}}if bytes.Equal(j, []byte("null")) {
+		*{{.Recv}} = {{.Null}}
+		return nil
+	}
+	{{end}}var str string
+	if err := json.Unmarshal(j, &str); err != nil {
+		return err
+	}
+	value, err := Parse{{.Type}}(str)
+	if err != nil {
+		return err
+	}
+	*{{.Recv}} = value
+	return nil
+}
+`))
+
+var parseScanTemplate = template.Must(template.New("").Parse(`
+// Scan implements the database/sql.Scanner interface for {{.Type}} using Parse{{.Type}}
+func ({{.Recv}} *{{.Type}}) Scan(value any) error {
+	var str string
+	switch value := value.(type) {
+	case string:
+		str = value
+	case []byte:
+		str = string(value)
+	case nil:
+		{{if .IsNullable}}*{{.Recv}} = {{.Null}}
+		return nil{{else}}return fmt.Errorf("can't scan SQL NULL as {{.Package}}.{{.Type}}"){{end}}
+	default:
+		return fmt.Errorf("can't scan SQL value of type %T as {{.Package}}.{{.Type}}", value)
+	}
+	parsed, err := Parse{{.Type}}(str)
+	if err != nil {
+		return err
+	}
+	*{{.Recv}} = parsed
+	return nil
+}
+`))
+
+// Unknown value method and decoding methods of integer enums mapping
+// unrecognized values to the unknown value (marked with //#unknown)
+// and calling the OnDeprecated<Type> hook (,ondeprecated flag).
+
+var isUnknownTemplate = template.Must(template.New("").Parse(`
+// IsUnknown returns true if {{.Recv}} is the unknown value {{.Unknown}}
+// or none of the valid values for {{.Type}}{{if .KeepUnknown}}
+// like unrecognized input kept by the decoding methods{{end}}
+func ({{.Recv}} {{.Type}}) IsUnknown() bool {
+	return {{.Recv}} == {{.Unknown}} || !{{.Recv}}.Valid()
+}
+`))
+
+// decodedTemplate defines the "decoded" template shared by the decoding
+// methods of integer enums, applied to the decoded value at the receiver.
+const decodedTemplate = `{{define "decoded"}}{{if and .Unknown (not .KeepUnknown)}}if !{{.Recv}}.Valid() {
+		*{{.Recv}} = {{.Unknown}}
+	}
+	{{end}}{{if and .Strict (not .Unknown)}}if err := {{.Recv}}.Validate(); err != nil {
+		return err
+	}
+	{{end}}{{if .CallsOnDeprecated}}if OnDeprecated{{.Type}} != nil && {{.Recv}}.IsDeprecated() {
+		OnDeprecated{{.Type}}(*{{.Recv}})
+	}
+	{{end}}{{end}}`
+
+var intUnmarshalJSONTemplate = template.Must(template.New("").Parse(`
+// UnmarshalJSON implements encoding/json.Unmarshaler{{if .KeepUnknown}}{{else if .Unknown}}
+// by decoding unrecognized values as {{.Unknown}}{{else if .Strict}}
+// returning an error for invalid values{{end}}{{if .CallsOnDeprecated}}
+// calling OnDeprecated{{.Type}} for deprecated values{{end}}
+func ({{.Recv}} *{{.Type}}) UnmarshalJSON(j []byte) error {
+	{{if .IsNullable}}if bytes.Equal(j, []byte("null")) {
+		*{{.Recv}} = {{.Null}}
+		return nil
+	}
+	{{end}}var value {{.Underlying}}
+	if err := json.Unmarshal(j, &value); err != nil {
+		return err
+	}
+	*{{.Recv}} = {{.Type}}(value)
+	{{template "decoded" .}}return nil
+}
+` + decodedTemplate))
+
+var intScanTemplate = template.Must(template.New("").Parse(`
+// Scan implements the database/sql.Scanner interface for {{.Type}}{{if .KeepUnknown}}{{else if .Unknown}}
+// by scanning unrecognized values as {{.Unknown}}{{else if .Strict}}
+// returning an error for invalid values{{end}}{{if .CallsOnDeprecated}}
+// calling OnDeprecated{{.Type}} for deprecated values{{end}}
+func ({{.Recv}} *{{.Type}}) Scan(value any) error {
+	switch value := value.(type) {
+	case int64:
+		*{{.Recv}} = {{.Type}}(value)
+	case float64:
+		*{{.Recv}} = {{.Type}}(value)
+	case nil:
+		{{if .IsNullable}}*{{.Recv}} = {{.Null}}
+		return nil{{else}}return fmt.Errorf("can't scan SQL NULL as {{.Package}}.{{.Type}}"){{end}}
+	default:
+		return fmt.Errorf("can't scan SQL value of type %T as {{.Package}}.{{.Type}}", value)
+	}
+	{{template "decoded" .}}return nil
+}
+` + decodedTemplate))
+
 // Scan + Value templates per underlying type, split per method so
 // `//#custom` can target Scan or Value individually.
 
@@ -166,9 +724,108 @@ func ({{.Recv}} {{.Type}}) Value() (driver.Value, error) {
 }
 `))
 
+// pgtype scanner and valuer methods and the type registration function
+// for github.com/jackc/pgx/v5. Generated for enum types with the ,pgx flag.
+// SQL NULL is mapped to the null value, other values are validated
+// unless unrecognized values are kept with //#unknown:keep.
+
+var pgxScanTextTemplate = template.Must(template.New("").Parse(`
+// ScanText implements the github.com/jackc/pgx/v5/pgtype.TextScanner interface
+// for {{.Type}}{{if .HasParser}} using Parse{{.Type}}{{else}} returning an error for invalid values{{end}}
+func ({{.Recv}} *{{.Type}}) ScanText(v pgtype.Text) error {
+	if !v.Valid {
+		{{if .IsNullable}}*{{.Recv}} = {{.Null}}
+		return nil{{else}}return fmt.Errorf("can't scan SQL NULL as {{.Package}}.{{.Type}}"){{end}}
+	}
+	{{if .HasParser}}value, err := Parse{{.Type}}(v.String)
+	if err != nil {
+		return err
+	}{{if not (or .StrictParser .Unknown)}}
+	if err := value.Validate(); err != nil {
+		return err
+	}{{end}}{{else}}value := {{.Type}}(v.String)
+	if err := value.Validate(); err != nil {
+		return err
+	}{{end}}
+	*{{.Recv}} = value
+	return nil
+}
+`))
+
+var pgxTextValueTemplate = template.Must(template.New("").Parse(`
+// TextValue implements the github.com/jackc/pgx/v5/pgtype.TextValuer interface
+// for {{.Type}}{{if .IsNullable}} returning SQL NULL for {{.Null}}{{end}}{{if not .KeepUnknown}}
+// and an error for invalid values{{end}}
+func ({{.Recv}} {{.Type}}) TextValue() (pgtype.Text, error) {
+	{{if .IsNullable}}if {{.Recv}} == {{.Null}} {
+		return pgtype.Text{}, nil
+	}
+	{{end}}{{if not .KeepUnknown}}if err := {{.Recv}}.Validate(); err != nil {
+		return pgtype.Text{}, err
+	}
+	{{end}}return pgtype.Text{String: string({{.Recv}}), Valid: true}, nil
+}
+`))
+
+var pgxScanInt64Template = template.Must(template.New("").Parse(`
+// ScanInt64 implements the github.com/jackc/pgx/v5/pgtype.Int64Scanner interface
+// for {{.Type}}{{if .KeepUnknown}}{{else if .Unknown}} by scanning unrecognized values as {{.Unknown}}{{else}} returning an error for invalid values{{end}}{{if .CallsOnDeprecated}}
+// calling OnDeprecated{{.Type}} for deprecated values{{end}}
+func ({{.Recv}} *{{.Type}}) ScanInt64(v pgtype.Int8) error {
+	if !v.Valid {
+		{{if .IsNullable}}*{{.Recv}} = {{.Null}}
+		return nil{{else}}return fmt.Errorf("can't scan SQL NULL as {{.Package}}.{{.Type}}"){{end}}
+	}
+	value := {{.Type}}(v.Int64)
+	{{if ne .Underlying "int64"}}if int64(value) != v.Int64 {
+		return fmt.Errorf("invalid value %d for type {{.Package}}.{{.Type}}", v.Int64)
+	}
+	{{end}}{{if .KeepUnknown}}{{else if .Unknown}}if !value.Valid() {
+		value = {{.Unknown}}
+	}
+	{{else}}if err := value.Validate(); err != nil {
+		return err
+	}
+	{{end}}{{if .CallsOnDeprecated}}if OnDeprecated{{.Type}} != nil && value.IsDeprecated() {
+		OnDeprecated{{.Type}}(value)
+	}
+	{{end}}*{{.Recv}} = value
+	return nil
+}
+`))
+
+var pgxInt64ValueTemplate = template.Must(template.New("").Parse(`
+// Int64Value implements the github.com/jackc/pgx/v5/pgtype.Int64Valuer interface
+// for {{.Type}}{{if .IsNullable}} returning SQL NULL for {{.Null}}{{end}}{{if not .KeepUnknown}}
+// and an error for invalid values{{end}}
+func ({{.Recv}} {{.Type}}) Int64Value() (pgtype.Int8, error) {
+	{{if .IsNullable}}if {{.Recv}} == {{.Null}} {
+		return pgtype.Int8{}, nil
+	}
+	{{end}}{{if not .KeepUnknown}}if err := {{.Recv}}.Validate(); err != nil {
+		return pgtype.Int8{}, err
+	}
+	{{end}}return pgtype.Int8{Int64: int64({{.Recv}}), Valid: true}, nil
+}
+`))
+
+var pgxRegisterTemplate = template.Must(template.New("").Parse(`
+// Register{{.Type}}PgType registers the Postgres {{if .IsStringType}}enum{{else}}domain{{end}} type {{.SQLName}}
+// with its oid in m and makes it the default type for {{.Type}} values.
+// The oid can be queried with SELECT '{{.SQLName}}'::regtype::oid.
+func Register{{.Type}}PgType(m *pgtype.Map, oid uint32) {
+	m.RegisterType(&pgtype.Type{Name: "{{.SQLName}}", OID: oid, Codec: {{.PGXCodec}}})
+	m.RegisterDefaultPgType({{.Type}}({{.ZeroValue}}), "{{.SQLName}}")
+}
+`))
+
 // jsonSchemaMethodTemplate provides the JSONSchema method for generating JSON Schema definitions.
 // Generated for enum types with the ,jsonschema flag.
-// Supports both nullable and non-nullable enums.
+// Supports both nullable and non-nullable enums,
+// the default is the //#default or the //#null value, see Enum.SchemaDefault.
+// If any enum constant has a description or is deprecated, the values are
+// also described as oneOf const schemas with the descriptions of the constants
+// and deprecated annotations, like in the standalone JSON Schema export.
 var jsonSchemaMethodTemplate = template.Must(template.New("").Parse(`
 // JSONSchema returns a github.com/invopop/jsonschema.Schema for {{.Type}}
 func ({{.Type}}) JSONSchema() *jsonschema.Schema {
@@ -178,14 +835,46 @@ func ({{.Type}}) JSONSchema() *jsonschema.Schema {
 				Type: "{{.JSONType}}",
 				Enum: []any{
 					{{range .JSONSchemaEnum}}{{.}},
-{{end}}},
+{{end}}},{{template "descriptions" .}}
 			},
 			{Type: "null"},
-		},
-		Default: {{.Null}},{{else}}Type: "{{.JSONType}}",
+		},{{else}}Type: "{{.JSONType}}",
 		Enum: []any{
 			{{range .JSONSchemaEnum}}{{.}},
-{{end}}},{{end}}
+{{end}}},{{template "descriptions" .}}{{end}}{{with .SchemaDefault}}
+		Default: {{.}},{{end}}
 	}
 }
+{{define "descriptions"}}{{if .JSONSchemaOneOf}}
+		OneOf: []*jsonschema.Schema{
+			{{$descriptions := .JSONSchemaDescriptions}}{{$deprecated := .JSONSchemaDeprecated}}{{range $index, $element := .JSONSchemaEnum}}{Const: {{$element}}{{with index $descriptions $index}}, Description: {{printf "%q" .}}{{end}}{{if index $deprecated $index}}, Deprecated: true{{end}}},
+{{end}}},{{end}}{{end}}`))
+
+// assertTemplate provides the compile-time assertion that the enum type
+// implements the generic github.com/ungerik/go-enum/enum.Enum interface.
+// Generated for enum types with the ,assert flag.
+var assertTemplate = template.Must(template.New("").Parse(`
+var _ enum.Enum[{{.Type}}] = {{.Type}}({{.ZeroValue}})
+`))
+
+// registryInitTemplate provides the init function registering the enum type
+// with the github.com/ungerik/go-enum/enumreg package.
+// Generated for enum types with the ,registry flag.
+var registryInitTemplate = template.Must(template.New("").Parse(`
+// init registers {{.Type}} with the github.com/ungerik/go-enum/enumreg package
+func init() {
+	enumreg.Register(enumreg.Definition[{{.Type}}]{
+		Names: []string{
+			{{range .Enums}}"{{.}}",
+{{end}}},
+		Values: []{{.Type}}{
+			{{range .Enums}}{{.}},
+{{end}}},{{if .IsNullable}}
+		Nullable: true,
+		Null:     {{.Null}},{{end}}{{if .HasDescriptions}}
+		Descriptions: []string{
+			{{range .Descriptions}}{{printf "%q" .}},
+{{end}}},{{end}}
+	})
+}
 `))
@@ -0,0 +1,142 @@
+package enums
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// TypeScript returns the source of a TypeScript module declaring
+// the passed enums of a single Go package.
+//
+// For every enum a const per value, a union type of the value literals,
+// a readonly array of the values, and a type guard function are declared.
+// The null value of a nullable enum is represented by the TypeScript null
+// and not included in the values array, like it is marshaled to JSON.
+// Doc comments of the enum constants are carried over as JSDoc comments.
+func TypeScript(enums []*Enum) ([]byte, error) {
+	var b bytes.Buffer
+	if len(enums) > 0 {
+		fmt.Fprintf(&b, "// Code generated by go-enum from Go package %s. DO NOT EDIT.\n", enums[0].Package)
+	}
+	for _, enum := range enums {
+		values, err := enum.LiteralValues()
+		if err != nil {
+			return nil, err
+		}
+		var (
+			literals    []string
+			valueConsts []string
+		)
+		b.WriteString("\n")
+		for i, name := range enum.Enums {
+			literal := "null"
+			if name != enum.Null {
+				literal = jsonLiteral(values[i])
+				literals = append(literals, literal)
+				valueConsts = append(valueConsts, name)
+			}
+			writeJSDoc(&b, "", enum.Descriptions[i])
+			fmt.Fprintf(&b, "export const %s = %s;\n", name, literal)
+		}
+		if enum.IsNullable() {
+			literals = append(literals, "null")
+		}
+
+		fmt.Fprintf(&b, "\n/** %s is the Go enum type %s.%s */\n", enum.Type, enum.Package, enum.Type)
+		fmt.Fprintf(&b, "export type %s =\n", enum.Type)
+		for i, literal := range literals {
+			fmt.Fprintf(&b, "  | %s", literal)
+			if i < len(literals)-1 {
+				b.WriteString("\n")
+			}
+		}
+		b.WriteString(";\n")
+
+		fmt.Fprintf(&b, "\n/** %sValues are all non-null values of %s */\n", enum.Type, enum.Type)
+		fmt.Fprintf(&b, "export const %sValues: readonly %s[] = [\n", enum.Type, enum.Type)
+		for _, name := range valueConsts {
+			fmt.Fprintf(&b, "  %s,\n", name)
+		}
+		b.WriteString("];\n")
+
+		fmt.Fprintf(&b, "\n/** is%s returns true if value is a valid %s */\n", enum.Type, enum.Type)
+		fmt.Fprintf(&b, "export function is%s(value: unknown): value is %s {\n", enum.Type, enum.Type)
+		if enum.IsNullable() {
+			fmt.Fprintf(&b, "  return value === null || (%sValues as readonly unknown[]).includes(value);\n", enum.Type)
+		} else {
+			fmt.Fprintf(&b, "  return (%sValues as readonly unknown[]).includes(value);\n", enum.Type)
+		}
+		b.WriteString("}\n")
+	}
+	return b.Bytes(), nil
+}
+
+// writeJSDoc writes text as JSDoc comment with the given indentation,
+// nothing for an empty text.
+func writeJSDoc(w io.Writer, indent, text string) {
+	if text == "" {
+		return
+	}
+	text = strings.ReplaceAll(text, "*/", `*\/`)
+	lines := strings.Split(text, "\n")
+	if len(lines) == 1 {
+		fmt.Fprintf(w, "%s/** %s */\n", indent, text)
+		return
+	}
+	fmt.Fprintf(w, "%s/**\n", indent)
+	for _, line := range lines {
+		fmt.Fprintf(w, "%s *%s\n", indent, strings.TrimRight(" "+line, " "))
+	}
+	fmt.Fprintf(w, "%s */\n", indent)
+}
+
+// WriteTypeScript generates a TypeScript file per Go package
+// with the enums found at path, see TypeScript.
+//
+// The files are named <package>.enums.ts and written to outDir,
+// or to the Go package directories if outDir is empty.
+//
+// Parameters:
+//   - path: Directory or file path to process, may end with "..." to recurse
+//   - outDir: Directory for the generated files (empty for package directories)
+//   - verboseOut: Writer for verbose progress output (nil to disable)
+//   - resultOut: Writer for generated code output (nil to write to files)
+//   - validate: If true, only check that the files are up to date, outdated files are returned as Result.Findings
+func WriteTypeScript(path, outDir string, verboseOut, resultOut io.Writer, validate bool) (*Result, error) {
+	enums, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string][]byte)
+	for _, pkgEnums := range groupByPackage(enums) {
+		dir := outDir
+		if dir == "" {
+			dir = pkgEnums[0].Dir
+		}
+		filePath := filepath.Join(dir, pkgEnums[0].Package+".enums.ts")
+		if _, exists := files[filePath]; exists {
+			return nil, fmt.Errorf("packages in different directories would write the same file %s", filePath)
+		}
+		files[filePath], err = TypeScript(pkgEnums)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return writeArtifacts(files, verboseOut, resultOut, validate)
+}
+
+// groupByPackage splits enums sorted by Load into
+// slices of enums per package directory.
+func groupByPackage(enums []*Enum) [][]*Enum {
+	var groups [][]*Enum
+	for i, enum := range enums {
+		if i == 0 || enum.Dir != enums[i-1].Dir {
+			groups = append(groups, nil)
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], enum)
+	}
+	return groups
+}
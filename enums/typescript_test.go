@@ -0,0 +1,107 @@
+package enums
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypeScript_StringEnum(t *testing.T) {
+	source := `package example
+
+type Status string //#enum
+
+const (
+	// StatusPending is waiting for confirmation
+	StatusPending   Status = "pending"
+	StatusConfirmed Status = ` + "`confirmed`" + `
+)`
+
+	fset, pkg, astFile := parseSource(t, source)
+	enums, err := Find(fset, pkg, astFile)
+	require.NoError(t, err)
+
+	ts, err := TypeScript([]*Enum{enums["Status"]})
+	require.NoError(t, err)
+	result := string(ts)
+
+	assert.Contains(t, result, "// Code generated by go-enum from Go package example. DO NOT EDIT.")
+	assert.Contains(t, result, "/** StatusPending is waiting for confirmation */\nexport const StatusPending = \"pending\";")
+	assert.Contains(t, result, `export const StatusConfirmed = "confirmed";`)
+	assert.Contains(t, result, "export type Status =\n  | \"pending\"\n  | \"confirmed\";")
+	assert.Contains(t, result, "export const StatusValues: readonly Status[] = [\n  StatusPending,\n  StatusConfirmed,\n];")
+	assert.Contains(t, result, "export function isStatus(value: unknown): value is Status {")
+	assert.NotContains(t, result, "| null")
+}
+
+func TestTypeScript_NullableIntEnum(t *testing.T) {
+	source := `package example
+
+type Priority int //#enum
+
+const (
+	PriorityNull Priority = 0 //#null
+	PriorityLow  Priority = 0x1
+	PriorityHigh Priority = 2
+)`
+
+	fset, pkg, astFile := parseSource(t, source)
+	enums, err := Find(fset, pkg, astFile)
+	require.NoError(t, err)
+
+	ts, err := TypeScript([]*Enum{enums["Priority"]})
+	require.NoError(t, err)
+	result := string(ts)
+
+	assert.Contains(t, result, "export const PriorityNull = null;")
+	assert.Contains(t, result, "export const PriorityLow = 1;")
+	assert.Contains(t, result, "export type Priority =\n  | 1\n  | 2\n  | null;")
+	// The null value is not part of the values array
+	assert.Contains(t, result, "export const PriorityValues: readonly Priority[] = [\n  PriorityLow,\n  PriorityHigh,\n];")
+	assert.Contains(t, result, "return value === null || (PriorityValues as readonly unknown[]).includes(value);")
+}
+
+func TestWriteTypeScript(t *testing.T) {
+	tmpDir := t.TempDir()
+	outDir := filepath.Join(tmpDir, "frontend")
+
+	source := `package example
+
+type Status string //#enum
+
+const (
+	StatusPending Status = "pending"
+	StatusActive  Status = "active"
+)
+`
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "status.go"), []byte(source), 0644))
+
+	// Missing file fails validation
+	result, err := WriteTypeScript(tmpDir, outDir, nil, nil, true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing or outdated")
+	require.Len(t, result.Findings, 1)
+	assert.Equal(t, filepath.Join(outDir, "example.enums.ts")+": missing or outdated", result.Findings[0].String())
+	require.Len(t, result.Files, 1)
+	assert.True(t, result.Files[0].Changed())
+
+	var output bytes.Buffer
+	_, err = WriteTypeScript(tmpDir, outDir, nil, &output, false)
+	require.NoError(t, err)
+	assert.Contains(t, output.String(), "export type Status =")
+	assert.NoFileExists(t, filepath.Join(outDir, "example.enums.ts"))
+
+	_, err = WriteTypeScript(tmpDir, outDir, nil, nil, false)
+	require.NoError(t, err)
+	written, err := os.ReadFile(filepath.Join(outDir, "example.enums.ts"))
+	require.NoError(t, err)
+	assert.Equal(t, output.String(), string(written))
+
+	// Generation is deterministic, so the written file validates
+	_, err = WriteTypeScript(tmpDir, outDir, nil, nil, true)
+	require.NoError(t, err)
+}
@@ -0,0 +1,247 @@
+package enums
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/ungerik/go-astvisit"
+)
+
+// TemplateFileExt is the file extension of
+// user-defined templates loaded by LoadTemplates.
+const TemplateFileExt = ".tmpl"
+
+// Templates are user-defined text/templates by name
+// that enum types can use with the //#enum,template=name option,
+// see Options.Templates.
+//
+// A template is executed with the *Enum like the built-in templates
+// and has to produce Go source with optional import declarations followed
+// by functions, methods of the enum type, or single variable declarations.
+// Every generated declaration is replaced on the next run,
+// can be overridden with //#custom, and is checked by ValidateRewrite.
+// The imports are added for the declarations referencing them.
+type Templates map[string]*template.Template
+
+// Add parses text as the user-defined template with the name.
+func (t Templates) Add(name, text string) error {
+	if name == "" || strings.ContainsAny(name, ",|=") {
+		return fmt.Errorf("invalid template name %q", name)
+	}
+	if _, exists := t[name]; exists {
+		return fmt.Errorf("template %q already added", name)
+	}
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return err
+	}
+	t[name] = tmpl
+	return nil
+}
+
+// LoadTemplates returns all files with the extension TemplateFileExt
+// in dir as user-defined templates named after the file without extension.
+func LoadTemplates(dir string) (Templates, error) {
+	filePaths, err := filepath.Glob(filepath.Join(dir, "*"+TemplateFileExt))
+	if err != nil {
+		return nil, err
+	}
+	if len(filePaths) == 0 {
+		return nil, fmt.Errorf("no %s template files in %s", TemplateFileExt, dir)
+	}
+	templates := make(Templates, len(filePaths))
+	for _, filePath := range filePaths {
+		text, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+		name := strings.TrimSuffix(filepath.Base(filePath), TemplateFileExt)
+		if err := templates.Add(name, string(text)); err != nil {
+			return nil, fmt.Errorf("%s: %w", filePath, err)
+		}
+	}
+	return templates, nil
+}
+
+// runTemplates returns the user-defined templates of a run,
+// options.Templates or the templates of the Templates directory of config.
+func runTemplates(options Options, config *Config) (Templates, error) {
+	if options.Templates != nil || options.FS != nil {
+		return options.Templates, nil
+	}
+	dir := config.TemplatesDir()
+	if dir == "" {
+		return nil, nil
+	}
+	return LoadTemplates(dir)
+}
+
+// renderedDecl is a declaration rendered by a user-defined template
+// used as the template of a methodTemplate.
+type renderedDecl []byte
+
+func (r renderedDecl) Execute(w io.Writer, _ any) error {
+	_, err := w.Write(r)
+	return err
+}
+
+// renderUserTemplates executes the user-defined templates of the enum
+// and splits their output into declarations that are generated like
+// the ones of the built-in templates.
+// Unknown templates are skipped here
+// and reported by checkUserTemplates.
+func (e *Enum) renderUserTemplates(templates Templates) error {
+	e.templates = templates
+	builtin := e.methodTemplates()
+	for _, name := range e.Templates {
+		tmpl := templates[name]
+		if tmpl == nil {
+			continue
+		}
+		var out bytes.Buffer
+		if err := tmpl.Execute(&out, e); err != nil {
+			return fmt.Errorf("template %q for enum type %s.%s: %w", name, e.Package, e.Type, err)
+		}
+		decls, err := e.parseUserDecls(out.Bytes())
+		if err != nil {
+			return fmt.Errorf("template %q for enum type %s.%s: %w", name, e.Package, e.Type, err)
+		}
+		for _, decl := range decls {
+			if slices.ContainsFunc(append(builtin, e.userDecls...), func(t methodTemplate) bool {
+				return t.name == decl.name && t.kind == decl.kind
+			}) {
+				return fmt.Errorf("template %q for enum type %s.%s generates %s which is already generated", name, e.Package, e.Type, decl.name)
+			}
+			e.userDecls = append(e.userDecls, decl)
+		}
+	}
+	return nil
+}
+
+// parseUserDecls parses the output of a user-defined template
+// into one methodTemplate per declaration with the imports
+// of the output it references, see declImports.
+func (e *Enum) parseUserDecls(output []byte) ([]methodTemplate, error) {
+	const header = "package enums\n"
+	source := append([]byte(header), output...)
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", source, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Go source: %w", err)
+	}
+	var decls []methodTemplate
+	for _, decl := range file.Decls {
+		var (
+			name string
+			kind declKind
+			doc  *ast.CommentGroup
+		)
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			name, kind, doc = decl.Name.Name, funcKind, decl.Doc
+			if decl.Recv != nil {
+				kind = methodKind
+				recvType := strings.TrimPrefix(astvisit.ExprString(decl.Recv.List[0].Type), "*")
+				if recvType != e.Type {
+					return nil, fmt.Errorf("method %s has receiver type %s instead of %s", name, recvType, e.Type)
+				}
+			} else if name == "init" {
+				return nil, errors.New("init functions are not supported")
+			}
+		case *ast.GenDecl:
+			if decl.Tok == token.IMPORT {
+				continue
+			}
+			name, kind, doc = varDeclName(decl), varKind, decl.Doc
+			if name == "" || name == "_" {
+				return nil, errors.New("only functions, methods, and single named variable declarations are supported")
+			}
+		}
+		start := decl.Pos()
+		if doc != nil {
+			start = doc.Pos()
+		}
+		src := source[fset.Position(start).Offset:fset.Position(decl.End()).Offset]
+		decls = append(decls, methodTemplate{
+			name:    name,
+			tmpl:    renderedDecl("\n" + string(src) + "\n"),
+			imports: declImports(file, decl),
+			kind:    kind,
+		})
+	}
+	return decls, nil
+}
+
+// declImports returns the import lines of file
+// with package names referenced by decl.
+// Blank and dot imports and imports whose package name is not
+// referenced by any declaration of the file, because it differs
+// from the guessed name, are returned for every declaration.
+func declImports(file *ast.File, decl ast.Decl) []string {
+	var imports []string
+	for _, imp := range file.Imports {
+		line := imp.Path.Value
+		if imp.Name != nil {
+			line = imp.Name.Name + " " + line
+		}
+		name := importName(imp)
+		if name == "_" || name == "." || usesPackage(decl, name) || !slices.ContainsFunc(file.Decls, func(d ast.Decl) bool { return usesPackage(d, name) }) {
+			imports = append(imports, line)
+		}
+	}
+	return imports
+}
+
+// importName returns the name of the imported package,
+// guessed from the last element of the import path
+// without major version suffix if the import is not named.
+func importName(imp *ast.ImportSpec) string {
+	if imp.Name != nil {
+		return imp.Name.Name
+	}
+	importPath, _ := strconv.Unquote(imp.Path.Value)
+	elems := strings.Split(importPath, "/")
+	name := elems[len(elems)-1]
+	if len(elems) > 1 && len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = elems[len(elems)-2]
+	}
+	name, _, _ = strings.Cut(name, ".")
+	return name
+}
+
+// usesPackage returns true if node references
+// an identifier of the package imported with name.
+func usesPackage(node ast.Node, name string) bool {
+	used := false
+	ast.Inspect(node, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok && id.Name == name && id.Obj == nil {
+				used = true
+			}
+		}
+		return !used
+	})
+	return used
+}
+
+// checkUserTemplates returns an error if a template
+// used by the enum is not one of the templates it was found with.
+func (e *Enum) checkUserTemplates() error {
+	for _, name := range e.Templates {
+		if e.templates[name] == nil {
+			return fmt.Errorf("enum type %s.%s in %s:%d uses unknown template %q", e.Package, e.Type, e.File, e.Line, name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+package enums
+
+import (
+	"context"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const entTestTemplate = `
+import "entgo.io/ent/schema/field"
+
+// EntField returns an ent enum field for {{.Type}}
+func {{.Type}}EntField(name string) *field.EnumBuilder {
+	return field.Enum(name).Values({{range .Literals}}{{.}}, {{end}})
+}
+
+// Values implements ent's field.EnumValues interface
+func ({{.Recv}} {{.Type}}) Values() []string {
+	return {{.Recv}}.EnumStrings()
+}
+
+var {{.Type}}EntName = "{{.Type}}"
+`
+
+func TestRewrite_UserTemplate(t *testing.T) {
+	templates := make(Templates)
+	require.NoError(t, templates.Add("ent-test", entTestTemplate))
+	assert.EqualError(t, templates.Add("ent-test", entTestTemplate), `template "ent-test" already added`)
+
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "status.go")
+	source := `package example
+
+type Status string //#enum,template=ent-test
+
+const (
+	StatusActive Status = "active"
+	StatusDone   Status = "done"
+)
+
+// Values is hand-written
+//
+//#custom
+func (x Status) Values() []string {
+	return []string{"active"}
+}
+`
+	require.NoError(t, os.WriteFile(testFile, []byte(source), 0644))
+
+	require.NoError(t, Rewrite(tmpDir, Options{Templates: templates}))
+	written, err := os.ReadFile(testFile)
+	require.NoError(t, err)
+	result := string(written)
+
+	assert.Contains(t, result, `"entgo.io/ent/schema/field"`)
+	assert.Contains(t, result, "// EntField returns an ent enum field for Status\nfunc StatusEntField(name string) *field.EnumBuilder {")
+	assert.Contains(t, result, `return field.Enum(name).Values("active", "done")`)
+	assert.Contains(t, result, `var StatusEntName = "Status"`)
+	// The custom method is kept and the receiver name of existing methods is used
+	assert.Contains(t, result, "return []string{\"active\"}")
+	assert.Equal(t, 1, strings.Count(result, ") Values() []string {"))
+	assert.Contains(t, result, "func (x Status) Valid() bool {")
+
+	// Rendered declarations are replaced, not duplicated
+	require.NoError(t, Rewrite(tmpDir, Options{Templates: templates}))
+	secondPass, err := os.ReadFile(testFile)
+	require.NoError(t, err)
+	assert.Equal(t, result, string(secondPass))
+	_, err = Run(context.Background(), Options{Path: tmpDir, Validate: true, Templates: templates})
+	require.NoError(t, err)
+
+	// Changed template output is detected as outdated
+	outdated := strings.Replace(result, `var StatusEntName = "Status"`, `var StatusEntName = "Other"`, 1)
+	require.NoError(t, os.WriteFile(testFile, []byte(outdated), 0644))
+	_, err = Run(context.Background(), Options{Path: tmpDir, Validate: true, Templates: templates})
+	assert.Error(t, err)
+}
+
+func TestRewrite_UserTemplateDeclImports(t *testing.T) {
+	templates := make(Templates)
+	require.NoError(t, templates.Add("ent-upper", `
+import (
+	"strings"
+
+	"entgo.io/ent/schema/field"
+)
+
+// EntField returns an ent enum field for {{.Type}}
+func {{.Type}}EntField(name string) *field.EnumBuilder {
+	return field.Enum(name).Values({{range .Literals}}{{.}}, {{end}})
+}
+
+// Upper returns the value in upper case
+func ({{.Recv}} {{.Type}}) Upper() string {
+	return strings.ToUpper(string({{.Recv}}))
+}
+`))
+
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "status.go")
+	source := `package example
+
+type Status string //#enum,template=ent-upper
+
+const (
+	StatusActive Status = "active"
+	StatusDone   Status = "done"
+)
+
+// StatusEntField is hand-written without ent
+//
+//#custom
+func StatusEntField(name string) string {
+	return name
+}
+`
+	require.NoError(t, os.WriteFile(testFile, []byte(source), 0644))
+
+	require.NoError(t, Rewrite(tmpDir, Options{Templates: templates}))
+	written, err := os.ReadFile(testFile)
+	require.NoError(t, err)
+	result := string(written)
+
+	assert.Contains(t, result, `"strings"`)
+	assert.Contains(t, result, "func (s Status) Upper() string {")
+	// The import of the overridden declaration is not added
+	assert.NotContains(t, result, "entgo.io")
+}
+
+func TestDeclImports(t *testing.T) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", `package enums
+
+import (
+	"strings"
+	_ "embed"
+	pgx "github.com/jackc/pgx/v5"
+	"gopkg.in/yaml.v3"
+	"github.com/jackc/pgx/v5/pgtype"
+	"example.com/go-unguessable"
+)
+
+func A(s string) string { return strings.ToUpper(s) }
+
+func B(strings []string) (*pgx.Conn, yaml.Node) { return nil, yaml.Node{Value: strings[0]} }
+
+var C pgtype.Text
+`, 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{`"strings"`, `_ "embed"`, `"example.com/go-unguessable"`}, declImports(file, file.Decls[1]))
+	assert.Equal(t, []string{`_ "embed"`, `pgx "github.com/jackc/pgx/v5"`, `"gopkg.in/yaml.v3"`, `"example.com/go-unguessable"`}, declImports(file, file.Decls[2]))
+	assert.Equal(t, []string{`_ "embed"`, `"github.com/jackc/pgx/v5/pgtype"`, `"example.com/go-unguessable"`}, declImports(file, file.Decls[3]))
+}
+
+func TestLoadTemplates(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "load-test.tmpl"), []byte(`
+// Kind returns the kind of {{.Type}}
+func ({{.Recv}} {{.Type}}) Kind() string {
+	return "enum"
+}
+`), 0644))
+	templates, err := LoadTemplates(dir)
+	require.NoError(t, err)
+
+	source := `package example
+
+type Status string //#enum,template=load-test
+
+const StatusActive Status = "active"`
+	fset, pkg, astFile := parseSource(t, source)
+	enums, err := find(fset, pkg, astFile, nil, templates)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"load-test"}, enums["Status"].Templates)
+	assert.True(t, enums["Status"].generates("Kind", methodKind))
+
+	// Loading again returns independent templates
+	again, err := LoadTemplates(dir)
+	require.NoError(t, err)
+	assert.Len(t, again, 1)
+
+	_, err = LoadTemplates(t.TempDir())
+	assert.ErrorContains(t, err, "no .tmpl template files")
+}
+
+func TestRun_ConfigTemplates(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module example.com/test\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ConfigFileName), []byte("templates: tmpl\n"), 0644))
+	require.NoError(t, os.Mkdir(filepath.Join(tmpDir, "tmpl"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "tmpl", "kind.tmpl"), []byte(`
+// Kind returns the kind of {{.Type}}
+func ({{.Recv}} {{.Type}}) Kind() string {
+	return "enum"
+}
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "status.go"), []byte(`package example
+
+type Status string //#enum,template=kind
+
+const StatusActive Status = "active"
+`), 0644))
+
+	// Every run loads the templates of the configuration again
+	for range 2 {
+		result, err := Run(context.Background(), Options{Path: tmpDir, DryRun: true})
+		require.NoError(t, err)
+		require.Len(t, result.Changed(), 1)
+		assert.Contains(t, string(result.Changed()[0].Output), "func (s Status) Kind() string {")
+	}
+}
+
+func TestUserTemplateErrors(t *testing.T) {
+	templates := make(Templates)
+	require.NoError(t, templates.Add("wrong-recv-test", `func (x Other) Foo() {}`))
+	require.NoError(t, templates.Add("builtin-test", `func ({{.Recv}} {{.Type}}) Valid() bool { return true }`))
+	require.NoError(t, templates.Add("invalid-test", `func {`))
+	require.NoError(t, templates.Add("const-test", `const X = 1`))
+	assert.EqualError(t, templates.Add("a,b", ""), `invalid template name "a,b"`)
+
+	tests := []struct {
+		template string
+		errMsg   string
+	}{
+		{"wrong-recv-test", "method Foo has receiver type Other instead of Status"},
+		{"builtin-test", `template "builtin-test" for enum type example.Status generates Valid which is already generated`},
+		{"invalid-test", "invalid Go source"},
+		{"const-test", "only functions, methods, and single named variable declarations are supported"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			fset, pkg, astFile := parseSource(t, `package example
+
+type Status string //#enum,template=`+tt.template+`
+
+const StatusActive Status = "active"`)
+			_, err := find(fset, pkg, astFile, nil, templates)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+
+	t.Run("unknown template", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "status.go"), []byte(`package example
+
+type Status string //#enum,template=not-registered
+
+const StatusActive Status = "active"
+`), 0644))
+		err := Rewrite(tmpDir, Options{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `uses unknown template "not-registered"`)
+	})
+}
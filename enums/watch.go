@@ -0,0 +1,479 @@
+package enums
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/ungerik/go-astvisit"
+)
+
+// Default intervals of WatchOptions
+const (
+	DefaultWatchInterval = 500 * time.Millisecond
+	DefaultWatchDebounce = 300 * time.Millisecond
+)
+
+// WatchOptions for Watch.
+type WatchOptions struct {
+	// Interval of polling the files for changes if file system
+	// notifications are not available or Poll is set,
+	// DefaultWatchInterval if zero
+	Interval time.Duration
+	// Poll forces polling the files for changes every Interval
+	// instead of using file system notifications,
+	// for example for network file systems without notifications
+	Poll bool
+	// Debounce is the time without further changes to wait
+	// after a change before rewriting, DefaultWatchDebounce if zero
+	Debounce time.Duration
+	// OnRun is called with the result of every Run that rewrote files,
+	// or with the error of a failed Run. Watch continues after errors.
+	OnRun func(*Result, error)
+}
+
+// fileState is used to detect changed files.
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// Watch runs Run with the options and then watches the Go source files
+// at options.Path for changes using file system notifications
+// until the context is canceled. If the notifications can't be set up,
+// or WatchOptions.Poll is set, the files are polled for changes instead.
+//
+// The files of the packages with changed files are rewritten after
+// no further changes happened for the Debounce time, but only if the
+// enums found in a file or their generated code changed, which also
+// depends on the other files of the package. Files written by Watch itself
+// don't trigger another run. Options.Files and Options.FS are not supported.
+//
+// Watch returns nil when the context is canceled,
+// or the error of the first Run.
+func Watch(ctx context.Context, options Options, watch WatchOptions) error {
+	if options.FS != nil || len(options.Files) > 0 {
+		return errors.New("watching doesn't support Options.FS and Options.Files")
+	}
+	if options.Path == "" {
+		options.Path = "."
+	}
+	if watch.Interval <= 0 {
+		watch.Interval = DefaultWatchInterval
+	}
+	if watch.Debounce <= 0 {
+		watch.Debounce = DefaultWatchDebounce
+	}
+	if options.Config == nil {
+		// Load the configuration only once
+		config, err := FindConfig(options.Path)
+		if err != nil {
+			return err
+		}
+		if config == nil {
+			config = &Config{}
+		}
+		options.Config = config
+	}
+
+	result, err := Run(ctx, options)
+	if err != nil {
+		return err
+	}
+	findHashes := make(map[string]string)
+	if err := updateFindHashes(ctx, options, result, findHashes); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		changes   = make(chan string)
+		watchErrs = make(chan error)
+	)
+	notifying := false
+	if !watch.Poll {
+		err := notifyChanges(ctx, options.Path, changes, watchErrs)
+		if err == nil {
+			notifying = true
+		} else if err := astvisit.FprintfVerbose(options.VerboseOut, "can't watch for file system notifications: %s\n", err); err != nil {
+			return err
+		}
+	}
+	if notifying {
+		if err := astvisit.FprintfVerbose(options.VerboseOut, "watching %s for changes\n", options.Path); err != nil {
+			return err
+		}
+	} else {
+		files, err := watchedFiles(options.Path)
+		if err != nil {
+			return err
+		}
+		go pollChanges(ctx, options.Path, files, watch.Interval, changes, watchErrs)
+		if err := astvisit.FprintfVerbose(options.VerboseOut, "polling %d files for changes every %s\n", len(files), watch.Interval); err != nil {
+			return err
+		}
+	}
+
+	// Report the first Run when changes are watched
+	if watch.OnRun != nil && len(result.Changed()) > 0 {
+		watch.OnRun(result, nil)
+	}
+
+	var (
+		debounce = time.NewTimer(watch.Debounce)
+		pending  = make(map[string]bool)
+		// written are the states of the files written by Watch
+		written = make(map[string]fileState)
+	)
+	debounce.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-watchErrs:
+			if watch.OnRun != nil {
+				watch.OnRun(nil, err)
+			}
+			continue
+		case path := <-changes:
+			if state, ok := written[path]; ok {
+				// Don't trigger on our own writes
+				if info, err := os.Stat(path); err == nil && state == (fileState{info.ModTime(), info.Size()}) {
+					continue
+				}
+				delete(written, path)
+			}
+			pending[path] = true
+			debounce.Reset(watch.Debounce)
+			continue
+		case <-debounce.C:
+		}
+
+		changed := slices.Sorted(maps.Keys(pending))
+		clear(pending)
+		result, err := watchRun(ctx, options, changed, findHashes)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if result != nil {
+			for _, file := range result.Changed() {
+				if info, err := os.Stat(file.Path); err == nil {
+					written[file.Path] = fileState{info.ModTime(), info.Size()}
+				}
+			}
+		}
+		if watch.OnRun != nil && (err != nil || result != nil) {
+			watch.OnRun(result, err)
+		}
+	}
+}
+
+// notifyChanges sends the paths of changed Go source files
+// at path to changes using file system notifications
+// until the context is canceled. An error is returned
+// if the notifications can't be set up for all directories.
+func notifyChanges(ctx context.Context, path string, changes chan<- string, errs chan<- error) error {
+	root, recursive, err := watchRoot(path)
+	if err != nil {
+		return err
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	// A single file is watched with the events of its package directory
+	if info, err := os.Stat(root); err == nil && !info.IsDir() {
+		err = watcher.Add(filepath.Dir(root))
+	} else {
+		err = addWatchedDirs(watcher, root, root, recursive)
+	}
+	if err != nil {
+		watcher.Close()
+		return err
+	}
+
+	go func() {
+		defer watcher.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Op == fsnotify.Chmod {
+					continue
+				}
+				if recursive && event.Has(fsnotify.Create) {
+					if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+						if err := addWatchedDirs(watcher, root, event.Name, true); err != nil {
+							select {
+							case errs <- err:
+							case <-ctx.Done():
+								return
+							}
+						}
+						continue
+					}
+				}
+				if !isWatchedFile(filepath.Base(event.Name)) {
+					continue
+				}
+				select {
+				case changes <- event.Name:
+				case <-ctx.Done():
+					return
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				select {
+				case errs <- err:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// addWatchedDirs adds dir and, if recursive,
+// the directories below it to the watcher.
+func addWatchedDirs(watcher *fsnotify.Watcher, root, dir string, recursive bool) error {
+	return walkWatched(root, dir, recursive, func(path string, entry fs.DirEntry) error {
+		if !entry.IsDir() {
+			return nil
+		}
+		return watcher.Add(path)
+	})
+}
+
+// pollChanges sends the paths of Go source files at path that changed
+// compared to the files states to changes every interval
+// until the context is canceled.
+func pollChanges(ctx context.Context, path string, files map[string]fileState, interval time.Duration, changes chan<- string, errs chan<- error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		current, err := watchedFiles(path)
+		if err != nil {
+			select {
+			case errs <- err:
+				continue
+			case <-ctx.Done():
+				return
+			}
+		}
+		var changed []string
+		for path, state := range current {
+			if files[path] != state {
+				changed = append(changed, path)
+			}
+		}
+		for path := range files {
+			if _, ok := current[path]; !ok {
+				changed = append(changed, path)
+			}
+		}
+		files = current
+		for _, path := range changed {
+			select {
+			case changes <- path:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// watchRun rewrites the source files of the packages of the changed files
+// whose find hash differs from findHashes.
+// It returns a nil Result if no file had to be rewritten.
+func watchRun(ctx context.Context, options Options, changed []string, findHashes map[string]string) (*Result, error) {
+	for _, path := range changed {
+		if _, err := os.Stat(path); err != nil {
+			delete(findHashes, path)
+		}
+	}
+	existing, err := affectedFiles(options.Path, changed)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) == 0 {
+		return nil, nil
+	}
+
+	dryRun := options
+	dryRun.Path = ""
+	dryRun.Files = existing
+	dryRun.DryRun = true
+	dryRun.VerboseOut = nil
+	dryResult, err := Run(ctx, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	var rewrite []string
+	for _, file := range dryResult.Files {
+		if file.Enums != nil && file.findHash != findHashes[file.Path] {
+			rewrite = append(rewrite, file.Path)
+		}
+	}
+	if len(rewrite) == 0 {
+		return nil, nil
+	}
+
+	options.Path = ""
+	options.Files = rewrite
+	result, err := Run(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+	return result, updateFindHashes(ctx, options, result, findHashes)
+}
+
+// updateFindHashes sets the find hashes of the source files of the result.
+// Files that were rewritten are found again, because their
+// existing declarations changed with the rewrite.
+func updateFindHashes(ctx context.Context, options Options, result *Result, findHashes map[string]string) error {
+	var rewritten []string
+	for _, file := range result.Files {
+		if file.Enums == nil {
+			continue
+		}
+		if file.Changed() && !options.DryRun && !options.Validate && options.ResultOut == nil {
+			rewritten = append(rewritten, file.Path)
+			continue
+		}
+		findHashes[file.Path] = file.findHash
+	}
+	if len(rewritten) == 0 {
+		return nil
+	}
+	options.Path = ""
+	options.Files = rewritten
+	options.DryRun = true
+	options.VerboseOut = nil
+	result, err := Run(ctx, options)
+	if err != nil {
+		return err
+	}
+	for _, file := range result.Files {
+		if file.Enums != nil {
+			findHashes[file.Path] = file.findHash
+		}
+	}
+	return nil
+}
+
+// affectedFiles returns the source files at path in the package
+// directories of the changed files without generated files.
+// The generated code of a file depends on the other files of its package,
+// like String methods or constants declared there, so all files
+// of a package have to be found again after a change.
+func affectedFiles(path string, changed []string) ([]string, error) {
+	root, _, err := watchRoot(path)
+	if err != nil {
+		return nil, err
+	}
+	dirs := make(map[string]bool)
+	for _, filePath := range changed {
+		dirs[filepath.Dir(filePath)] = true
+	}
+	if info, err := os.Stat(root); err == nil && !info.IsDir() {
+		// Only the watched file of its package
+		if dirs[filepath.Dir(root)] {
+			return []string{root}, nil
+		}
+		return nil, nil
+	}
+	var files []string
+	for _, dir := range slices.Sorted(maps.Keys(dirs)) {
+		err := walkWatched(dir, dir, false, func(filePath string, entry fs.DirEntry) error {
+			if !entry.IsDir() && !strings.HasSuffix(filePath, GeneratedFileSuffix) {
+				files = append(files, filePath)
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// watchRoot returns the absolute path of path
+// without "..." and if the path is recursive.
+func watchRoot(path string) (root string, recursive bool, err error) {
+	recursive = strings.HasSuffix(path, "...")
+	root, err = filepath.Abs(strings.TrimSuffix(path, "..."))
+	return root, recursive, err
+}
+
+// watchedFiles returns the state of the Go source files at path
+// that would be processed by Run, including generated files.
+// For a single file path the files of its package are returned.
+func watchedFiles(path string) (map[string]fileState, error) {
+	root, recursive, err := watchRoot(path)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(root); err == nil && !info.IsDir() {
+		root = filepath.Dir(root)
+	}
+	files := make(map[string]fileState)
+	err = walkWatched(root, root, recursive, func(filePath string, entry fs.DirEntry) error {
+		if entry.IsDir() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		files[filePath] = fileState{info.ModTime(), info.Size()}
+		return nil
+	})
+	return files, err
+}
+
+// walkWatched calls fn for the directories and Go source files
+// below dir that would be processed by Run for the root path.
+func walkWatched(root, dir string, recursive bool, fn func(path string, entry fs.DirEntry) error) error {
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := entry.Name()
+		if entry.IsDir() {
+			if path != root && (!recursive || (sourceFS{}).skipDir(path)) {
+				return filepath.SkipDir
+			}
+			return fn(path, entry)
+		}
+		if !isWatchedFile(name) {
+			return nil
+		}
+		return fn(path, entry)
+	})
+}
+
+// isWatchedFile returns true for the names of Go source files
+// that are processed by Run, including generated files.
+func isWatchedFile(name string) bool {
+	return strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go")
+}
@@ -0,0 +1,165 @@
+package enums
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatch(t *testing.T) {
+	t.Run("notify", func(t *testing.T) { testWatch(t, false) })
+	t.Run("poll", func(t *testing.T) { testWatch(t, true) })
+}
+
+func testWatch(t *testing.T, poll bool) {
+	tmpDir := t.TempDir()
+	statusFile := filepath.Join(tmpDir, "status.go")
+	require.NoError(t, os.WriteFile(statusFile, []byte(runTestSource), 0644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runs := make(chan *Result, 10)
+	done := make(chan error)
+	go func() {
+		done <- Watch(ctx, Options{Path: tmpDir}, WatchOptions{
+			Interval: 10 * time.Millisecond,
+			Poll:     poll,
+			Debounce: 20 * time.Millisecond,
+			OnRun: func(result *Result, err error) {
+				assert.NoError(t, err)
+				runs <- result
+			},
+		})
+	}()
+	defer func() {
+		cancel()
+		require.NoError(t, <-done)
+	}()
+
+	nextRun := func() *Result {
+		t.Helper()
+		select {
+		case result := <-runs:
+			return result
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for run")
+			return nil
+		}
+	}
+	noRun := func() {
+		t.Helper()
+		select {
+		case result := <-runs:
+			t.Fatalf("unexpected run changing %d files", len(result.Changed()))
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+	readSource := func() string {
+		t.Helper()
+		source, err := os.ReadFile(statusFile)
+		require.NoError(t, err)
+		return string(source)
+	}
+
+	// Initial run
+	result := nextRun()
+	require.Len(t, result.Changed(), 1)
+	assert.Contains(t, readSource(), "func (s Status) Valid() bool {")
+	// Own writes don't trigger a run
+	noRun()
+
+	// Changes not affecting enums don't trigger a rewrite
+	source := readSource() + "\nfunc helper() {}\n"
+	require.NoError(t, os.WriteFile(statusFile, []byte(source), 0644))
+	noRun()
+	assert.Equal(t, source, readSource())
+
+	// New enum values do
+	source = strings.Replace(source, `StatusInactive Status = "inactive"`, `StatusInactive Status = "inactive"
+	StatusDeleted  Status = "deleted"`, 1)
+	require.NoError(t, os.WriteFile(statusFile, []byte(source), 0644))
+	result = nextRun()
+	require.Len(t, result.Changed(), 1)
+	assert.Equal(t, statusFile, result.Changed()[0].Path)
+	assert.Contains(t, readSource(), "StatusDeleted,")
+	noRun()
+}
+
+func TestWatch_Package(t *testing.T) {
+	t.Run("notify", func(t *testing.T) { testWatchPackage(t, false) })
+	t.Run("poll", func(t *testing.T) { testWatchPackage(t, true) })
+}
+
+// testWatchPackage checks that changes in one file
+// of a package rewrite the other files depending on it.
+func testWatchPackage(t *testing.T, poll bool) {
+	tmpDir := t.TempDir()
+	levelFile := filepath.Join(tmpDir, "level.go")
+	baseFile := filepath.Join(tmpDir, "base.go")
+	require.NoError(t, os.WriteFile(levelFile, []byte("package example\n\ntype Level int //#enum,flag\n\nconst LevelLow Level = base\n"), 0644))
+	require.NoError(t, os.WriteFile(baseFile, []byte("package example\n\nconst base = 1\n"), 0644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runs := make(chan *Result, 10)
+	done := make(chan error)
+	go func() {
+		done <- Watch(ctx, Options{Path: tmpDir}, WatchOptions{
+			Interval: 10 * time.Millisecond,
+			Poll:     poll,
+			Debounce: 20 * time.Millisecond,
+			OnRun: func(result *Result, err error) {
+				assert.NoError(t, err)
+				runs <- result
+			},
+		})
+	}()
+	defer func() {
+		cancel()
+		require.NoError(t, <-done)
+	}()
+
+	nextRun := func() *Result {
+		t.Helper()
+		select {
+		case result := <-runs:
+			return result
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for run")
+			return nil
+		}
+	}
+	readLevel := func() string {
+		t.Helper()
+		source, err := os.ReadFile(levelFile)
+		require.NoError(t, err)
+		return string(source)
+	}
+
+	result := nextRun()
+	require.Len(t, result.Changed(), 1)
+	assert.Contains(t, readLevel(), "\t\t\"1\",\n")
+	assert.Contains(t, readLevel(), "func (l Level) String() string {")
+
+	// A changed constant in another file changes the values
+	require.NoError(t, os.WriteFile(baseFile, []byte("package example\n\nconst base = 5\n"), 0644))
+	result = nextRun()
+	require.Len(t, result.Changed(), 1)
+	assert.Equal(t, levelFile, result.Changed()[0].Path)
+	assert.Contains(t, readLevel(), "\t\t\"5\",\n")
+
+	// A String method in another file replaces the generated one
+	require.NoError(t, os.WriteFile(baseFile, []byte("package example\n\nconst base = 5\n\nfunc (l Level) String() string { return \"level\" }\n"), 0644))
+	result = nextRun()
+	require.Len(t, result.Changed(), 1)
+	assert.NotContains(t, readLevel(), "func (l Level) String() string {")
+}
+
+func TestWatch_Unsupported(t *testing.T) {
+	err := Watch(context.Background(), Options{Files: []string{"status.go"}}, WatchOptions{})
+	require.Error(t, err)
+}
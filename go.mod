@@ -3,17 +3,19 @@ module github.com/ungerik/go-enum
 go 1.24.0
 
 require (
+	github.com/fsnotify/fsnotify v1.9.0
 	github.com/stretchr/testify v1.11.1
 	github.com/ungerik/go-astvisit v0.0.0-20251017171216-b7bb0384dd33
+	golang.org/x/mod v0.31.0
+	golang.org/x/tools v0.40.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	golang.org/x/mod v0.31.0 // indirect
 	golang.org/x/sync v0.19.0 // indirect
-	golang.org/x/tools v0.40.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
+	golang.org/x/sys v0.39.0 // indirect
 )
 
 // replace github.com/ungerik/go-astvisit => ../go-astvisit
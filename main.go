@@ -8,6 +8,7 @@ manipulation provided by github.com/ungerik/go-astvisit.
 # Usage
 
 	go-enum [options] [path]
+	go-enum <command> [options] [path]
 
 # Options
 
@@ -17,8 +18,65 @@ manipulation provided by github.com/ungerik/go-astvisit.
 	-validate   Check for missing or outdated enum methods without modifying files.
 	            Reports issues to stderr and exits with code 1 if any are found.
 	            Useful for CI validation to ensure all enums have up-to-date methods.
+	            Also fails if an enums.lock.json file in the module root is outdated.
+	-templates  Directory with user-defined *.tmpl templates
+	            used by enums with the ,template=name option
+	-config     Configuration file to use instead of the go-enum.yaml
+	            file in the module root
+	-stdin      Read the source of the file given with -filename from stdin
+	            and write the rewritten source to stdout, for editor integration
+	-filename   Path of the file read with -stdin
+	-watch      Keep running and regenerate the enum methods of changed files
+	            until interrupted. Changes are detected with file system
+	            notifications, or by polling if they are not available.
 	-help       Show help message
 
+# Commands
+
+	catalog     Write the labels of the enums with ,i18n or //#label markers
+	            as message catalog for translators.
+	            Options: -format gotext|po, -lang tag, -out file, -print, -validate, -verbose
+	jsonschema  Write a JSON Schema or OpenAPI 3.1 components document
+	            with the schemas of all enums.
+	            Options: -format json|openapi, -out file, -print, -validate, -verbose
+	ts          Write a TypeScript file per package with union types,
+	            value arrays and type guards for the enums.
+	            Options: -out dir, -print, -validate, -verbose
+	sql         Write DDL declaring the enums as Postgres ENUM types and domains,
+	            or CHECK constraint comments for MySQL and SQLite. With -base a Postgres
+	            migration from the enums at a git reference is written instead.
+	            Options: -dialect postgres|mysql|sqlite, -base ref, -out file,
+	            -print, -validate, -verbose
+	compat      Compare the enums with a baseline and report removed values,
+	            changed literals, underlying types and null values as breaking,
+	            added values as non-breaking. Exits with code 1 for breaking changes.
+	            Options: -base git-ref|snapshot.json, -write-snapshot file
+	snapshot    Write the enums.lock.json file in the module root with the
+	            qualified name, values and literals of every enum of the module.
+	            Options: -print, -validate, -verbose
+	list        Print every enum with its package, file:line, underlying type,
+	            values, null constant, flags and custom methods.
+	            Options: -format table|json|csv
+	doc         Write Markdown or HTML documentation with a section per enum
+	            and a table of constants, values and descriptions.
+	            Options: -format md|html, -out file, -source-url url,
+	            -print, -validate, -verbose
+
+# Configuration
+
+An optional go-enum.yaml file in the module root directory (next to go.mod)
+sets defaults for all enums of the module and overrides per package:
+
+	options: [jsonschema, description]  # //#enum options for every enum type
+	layout: file                        # inline (default) or file for <source>_enum.go files
+	strict: true                        # decoders return an error for invalid values
+	intString: name                     # String of integer enums: number (default) or name
+	templates: tools/enumtemplates      # directory of user-defined templates
+	packages:
+	  internal/legacy:                  # package directory, ending with /... for sub-directories
+	    layout: inline
+	    strict: false
+
 # Exit Codes
 
 	0   Success (no issues found in validate mode, or generation completed successfully)
@@ -40,8 +98,8 @@ Mark a type as an enum with the //#enum comment:
 For all enums:
   - Valid() bool - Checks if value is valid
   - Validate() error - Returns error if invalid
-  - Enums() []T - Returns all enum values
-  - EnumStrings() []string - Returns all values as strings
+  - Enums() []T - Returns all enum values except deprecated ones
+  - EnumStrings() []string - Returns all values as strings except deprecated ones
 
 For string enums:
   - String() string - Implements fmt.Stringer
@@ -53,9 +111,66 @@ For nullable enums (marked with //#null):
   - MarshalJSON/UnmarshalJSON
   - Scan/Value for database/sql
 
+For enums with "Deprecated: " doc paragraphs or //#deprecated markers:
+  - AllIncludingDeprecated() []T - Returns all enum values
+  - IsDeprecated() bool - Returns true for deprecated values
+  - var OnDeprecated<Type> func(<Type>) - Hook called by decoders (only with ,ondeprecated)
+
+For enums with a //#default or //#default:empty value:
+  - Default<Type>() <Type> - Returns the default value
+  - SetDefault() - Sets the default value
+
+For enums with ,description flag:
+  - Description() string - Returns the doc comment of the constant
+
+For enums with //#label:"..." markers or the ,i18n flag:
+  - Label() string - Returns the human readable label
+  - LabelFor(language.Tag) string - Translated label (only with ,i18n)
+
+For enums with an //#unknown or //#unknown:keep value:
+  - IsUnknown() bool - Returns true for the unknown value or invalid values
+  - UnmarshalJSON/Scan mapping unrecognized integers to the unknown value
+
+For string enums with //#alias:"..." markers, an //#unknown or
+//#default:empty value, or the ,nocase or ,normalize=trim|lower|snake flags:
+  - Parse<Type>(string) (<Type>, error) - Accepts normalized values and aliases,
+    returns unrecognized strings as is unless strict
+  - UnmarshalText/UnmarshalJSON/Scan using Parse<Type>
+
+For enums with ,strict flag or strict: true configuration:
+  - Parse<Type>, UnmarshalText/UnmarshalJSON/Scan returning an error for invalid values
+
+For integer enums with ,string=name flag or intString: name configuration:
+  - String() string - Returns the constant name
+  - EnumStrings() []string - Returns the constant names instead of the numbers
+
 For enums with ,jsonschema flag:
   - JSONSchema() *jsonschema.Schema
 
+For enums with ,slog flag:
+  - LogValue() slog.Value - Label of valid values, raw value with invalid=true else
+
+For enums with ,flag or ,pflag flags:
+  - Set(string) error, Type() string - flag.Value and pflag.Value (with String)
+  - String() string - For integer enums without a String method in another file
+  - <Type>Var(fs *flag.FlagSet, ...) - Flag definition (only with ,flag)
+  - <Type>VarP(fs *pflag.FlagSet, ...) - Flag definition (only with ,pflag)
+  - Complete<Type>(toComplete string) []string - Shell completion (only with ,pflag)
+
+For enums with ,pgx flag:
+  - ScanText/TextValue - pgtype.TextScanner and pgtype.TextValuer of string enums
+  - ScanInt64/Int64Value - pgtype.Int64Scanner and pgtype.Int64Valuer of integer enums
+  - Register<Type>PgType(m *pgtype.Map, oid uint32) - Registers the Postgres type
+
+For enums with ,assert flag:
+  - var _ enum.Enum[T] - Compile-time assertion of github.com/ungerik/go-enum/enum.Enum
+
+For enums with ,registry flag:
+  - init() - Registers the type with github.com/ungerik/go-enum/enumreg
+
+For enums with ,template=name flag:
+  - Functions, methods, and variables of the user-defined template
+
 # Example
 
 	//go:generate go-enum
@@ -71,10 +186,13 @@ For enums with ,jsonschema flag:
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
+	"path/filepath"
 
 	"github.com/ungerik/go-enum/enums"
 )
@@ -85,13 +203,36 @@ var (
 	printOnly bool
 	validate  bool
 	printHelp bool
+
+	templatesDir string
+	configFile   string
+
+	stdin    bool
+	filename string
+
+	watch bool
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			if err := cmd(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, "go-enum error:", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
 	flag.BoolVar(&verbose, "verbose", false, "prints information to stdout of what's happening")
 	flag.BoolVar(&debug, "debug", false, "inserts debug information")
 	flag.BoolVar(&printOnly, "print", false, "prints to stdout instead of writing files")
 	flag.BoolVar(&validate, "validate", false, "check for missing or outdated enum methods without modifying files")
+	flag.StringVar(&templatesDir, "templates", "", "directory with user-defined *.tmpl templates for //#enum,template=name")
+	flag.StringVar(&configFile, "config", "", "configuration file to use instead of "+enums.ConfigFileName+" in the module root")
+	flag.BoolVar(&stdin, "stdin", false, "reads the source of the -filename file from stdin and writes the rewritten source to stdout")
+	flag.StringVar(&filename, "filename", "", "path of the file read with -stdin")
+	flag.BoolVar(&watch, "watch", false, "keeps running and regenerates the enum methods of changed files until interrupted")
 	flag.BoolVar(&printHelp, "help", false, "prints this help output")
 	flag.Parse()
 	if printHelp {
@@ -99,29 +240,120 @@ func main() {
 		os.Exit(2)
 	}
 
-	var (
-		path       = "."
-		verboseOut io.Writer
-		resultOut  io.Writer
-	)
+	path := "."
 	if args := flag.Args(); len(args) > 0 {
 		path = args[0]
 	}
-	if verbose {
-		verboseOut = os.Stdout
+	if stdin {
+		if filename == "" {
+			fmt.Fprintln(os.Stderr, "go-enum error: -stdin requires -filename")
+			os.Exit(2)
+		}
+		path = filename
 	}
-	if printOnly {
-		resultOut = os.Stdout
+	if watch && (stdin || validate || printOnly) {
+		fmt.Fprintln(os.Stderr, "go-enum error: -watch can't be combined with -stdin, -validate, or -print")
+		os.Exit(2)
 	}
+	if err := run(path); err != nil {
+		fmt.Fprintln(os.Stderr, "go-enum error:", err)
+		os.Exit(1)
+	}
+}
 
-	var err error
-	if validate {
-		err = enums.ValidateRewrite(path, verboseOut, debug)
+func run(path string) (err error) {
+	options := enums.Options{
+		Debug:    debug,
+		Validate: validate,
+	}
+	switch {
+	case verbose && stdin:
+		// Stdout is reserved for the rewritten source
+		options.VerboseOut = os.Stderr
+	case verbose:
+		options.VerboseOut = os.Stdout
+	}
+	if printOnly {
+		options.ResultOut = os.Stdout
+	}
+	if configFile != "" {
+		options.Config, err = enums.LoadConfig(configFile)
 	} else {
-		err = enums.Rewrite(path, verboseOut, resultOut, debug)
+		options.Config, err = enums.FindConfig(path)
 	}
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "go-enum error:", err)
-		os.Exit(1)
+		return err
+	}
+	if options.Config == nil {
+		// Don't look for the configuration file again
+		options.Config = &enums.Config{}
+	}
+	if templatesDir != "" {
+		// Otherwise the templates directory of the configuration is used
+		options.Templates, err = enums.LoadTemplates(templatesDir)
+		if err != nil {
+			return err
+		}
+	}
+	if stdin {
+		return rewriteStdin(path, options)
+	}
+	if watch {
+		return watchRewrite(path, options)
+	}
+	return enums.Rewrite(path, options)
+}
+
+// watchRewrite regenerates the enum methods of changed files
+// at path until the process is interrupted.
+func watchRewrite(path string, options enums.Options) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	options.Path = path
+	return enums.Watch(ctx, options, enums.WatchOptions{
+		OnRun: func(result *enums.Result, err error) {
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "go-enum error:", err)
+				return
+			}
+			for _, finding := range result.Findings {
+				fmt.Fprintln(os.Stderr, finding)
+			}
+			for _, file := range result.Changed() {
+				fmt.Println("go-enum: rewrote", file.Path)
+			}
+		},
+	})
+}
+
+// rewriteStdin writes the rewritten source of the file
+// at filePath read from stdin to stdout.
+func rewriteStdin(filePath string, options enums.Options) error {
+	source, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return err
+	}
+	rewritten, result, err := enums.RewriteFileSource(context.Background(), filePath, source, options)
+	if result != nil {
+		for _, finding := range result.Findings {
+			fmt.Fprintln(os.Stderr, finding)
+		}
+	}
+	if err != nil || options.Validate {
+		return err
+	}
+	// The generated file of the LayoutFile layout is not written,
+	// because only the buffer of the editor should change
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return err
+	}
+	for _, file := range result.Changed() {
+		if file.Path != absPath {
+			fmt.Fprintf(os.Stderr, "go-enum: %s is outdated, run go-enum without -stdin to update it\n", file.Path)
+		}
 	}
+	_, err = os.Stdout.Write(rewritten)
+	return err
 }